@@ -0,0 +1,313 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/kettek/goro/pathing"
+)
+
+// Animation changes a Screen's cells over time. Animations are stepped each time the Screen delivers an EventTick, drawing to the Screen's pending cells so that they are shown on the next Flush.
+type Animation interface {
+	// Step draws the animation as it is after elapsed time and returns whether it has finished.
+	Step(screen *Screen, elapsed time.Duration) (done bool)
+	// End draws the animation's final state. It is called once the animation finishes or is stopped.
+	End(screen *Screen)
+}
+
+// animationEntry is an Animation running on a Screen.
+type animationEntry struct {
+	animation Animation
+	elapsed   time.Duration
+}
+
+// Animate starts the animation on the Screen. It is first stepped on the next EventTick.
+func (screen *Screen) Animate(animation Animation) {
+	screen.animationsMutex.Lock()
+	defer screen.animationsMutex.Unlock()
+	screen.animations = append(screen.animations, &animationEntry{animation: animation})
+}
+
+// StopAnimation stops the animation, drawing its final state.
+func (screen *Screen) StopAnimation(animation Animation) {
+	screen.animationsMutex.Lock()
+	for i, entry := range screen.animations {
+		if entry.animation == animation {
+			screen.animations = append(screen.animations[:i], screen.animations[i+1:]...)
+			screen.animationsMutex.Unlock()
+			animation.End(screen)
+			return
+		}
+	}
+	screen.animationsMutex.Unlock()
+}
+
+// StopAnimations stops every animation, drawing their final states.
+func (screen *Screen) StopAnimations() {
+	screen.animationsMutex.Lock()
+	animations := screen.animations
+	screen.animations = nil
+	screen.animationsMutex.Unlock()
+	for _, entry := range animations {
+		entry.animation.End(screen)
+	}
+}
+
+// Animating returns whether any animations are running.
+func (screen *Screen) Animating() bool {
+	screen.animationsMutex.Lock()
+	defer screen.animationsMutex.Unlock()
+	return len(screen.animations) > 0
+}
+
+// StepAnimations advances each animation by delta, removing those that finish. It is called by Deliver for each EventTick.
+func (screen *Screen) StepAnimations(delta time.Duration) {
+	screen.animationsMutex.Lock()
+	animations := append([]*animationEntry(nil), screen.animations...)
+	screen.animationsMutex.Unlock()
+
+	var finished []*animationEntry
+	for _, entry := range animations {
+		entry.elapsed += delta
+		if entry.animation.Step(screen, entry.elapsed) {
+			finished = append(finished, entry)
+		}
+	}
+	if len(finished) == 0 {
+		return
+	}
+
+	screen.animationsMutex.Lock()
+	remaining := screen.animations[:0]
+	for _, entry := range screen.animations {
+		done := false
+		for _, f := range finished {
+			if entry == f {
+				done = true
+				break
+			}
+		}
+		if !done {
+			remaining = append(remaining, entry)
+		}
+	}
+	screen.animations = remaining
+	screen.animationsMutex.Unlock()
+
+	for _, entry := range finished {
+		entry.animation.End(screen)
+	}
+}
+
+// Deliver performs the work tied to the Screen's owner receiving an event: it records the event with the Screen's EventRecorders and, for an EventTick, steps the Screen's animations and particles. WaitEvent and its variants call it for you. Events received from the channel returned by Events must each be passed to Deliver, and it returns the event for convenience.
+func (screen *Screen) Deliver(event Event) Event {
+	screen.recordEvent(event)
+	if tick, ok := event.(EventTick); ok {
+		screen.StepAnimations(tick.Delta)
+	}
+	return event
+}
+
+// FadeForeground fades the foreground of the cell at X and Y from one color to another.
+type FadeForeground struct {
+	X, Y     int
+	From, To Color
+	Duration time.Duration
+	Easing   Easing
+}
+
+// Step sets the cell's foreground to the color between From and To.
+func (fade *FadeForeground) Step(screen *Screen, elapsed time.Duration) bool {
+	screen.SetForeground(fade.X, fade.Y, LerpColor(fade.From, fade.To, ease(fade.Easing, elapsed, fade.Duration)))
+	return elapsed >= fade.Duration
+}
+
+// End sets the cell's foreground to To.
+func (fade *FadeForeground) End(screen *Screen) {
+	screen.SetForeground(fade.X, fade.Y, fade.To)
+}
+
+// FadeBackground fades the background of the cell at X and Y from one color to another.
+type FadeBackground struct {
+	X, Y     int
+	From, To Color
+	Duration time.Duration
+	Easing   Easing
+}
+
+// Step sets the cell's background to the color between From and To.
+func (fade *FadeBackground) Step(screen *Screen, elapsed time.Duration) bool {
+	screen.SetBackground(fade.X, fade.Y, LerpColor(fade.From, fade.To, ease(fade.Easing, elapsed, fade.Duration)))
+	return elapsed >= fade.Duration
+}
+
+// End sets the cell's background to To.
+func (fade *FadeBackground) End(screen *Screen) {
+	screen.SetBackground(fade.X, fade.Y, fade.To)
+}
+
+// CycleRunes cycles the rune of the cell at X and Y through Runes, showing each for Interval. It runs for Duration, or until stopped if Duration is 0, and ends on the first rune.
+type CycleRunes struct {
+	X, Y     int
+	Runes    []rune
+	Interval time.Duration
+	Duration time.Duration
+}
+
+// Step sets the cell's rune to the current rune of the cycle.
+func (cycle *CycleRunes) Step(screen *Screen, elapsed time.Duration) bool {
+	if len(cycle.Runes) == 0 || cycle.Interval <= 0 {
+		return true
+	}
+	screen.SetRune(cycle.X, cycle.Y, cycle.Runes[int(elapsed/cycle.Interval)%len(cycle.Runes)])
+	return cycle.Duration > 0 && elapsed >= cycle.Duration
+}
+
+// End sets the cell's rune to the first rune.
+func (cycle *CycleRunes) End(screen *Screen) {
+	if len(cycle.Runes) > 0 {
+		screen.SetRune(cycle.X, cycle.Y, cycle.Runes[0])
+	}
+}
+
+// SlideRune moves a rune along Path over Duration, restoring the cells it passes over. It ends with the rune drawn at the last step if Stay is set, otherwise the last cell is restored as well.
+type SlideRune struct {
+	Path     []pathing.Step
+	Rune     rune
+	Style    Style
+	Duration time.Duration
+	Easing   Easing
+	Stay     bool
+
+	index int
+	under Cell
+	drawn bool
+}
+
+// Step moves the rune to the step of Path reached after elapsed time.
+func (slide *SlideRune) Step(screen *Screen, elapsed time.Duration) bool {
+	if len(slide.Path) == 0 {
+		return true
+	}
+	index := int(ease(slide.Easing, elapsed, slide.Duration) * float64(len(slide.Path)-1))
+	if index < 0 {
+		index = 0
+	} else if index >= len(slide.Path) {
+		index = len(slide.Path) - 1
+	}
+	if !slide.drawn || index != slide.index {
+		slide.restore(screen)
+		slide.index = index
+		step := slide.Path[index]
+		if cell, err := screen.pendingCell(step.X(), step.Y()); err == nil {
+			slide.under = cell
+			slide.drawn = true
+			screen.DrawRune(step.X(), step.Y(), slide.Rune, slide.Style)
+		}
+	}
+	return elapsed >= slide.Duration
+}
+
+// End leaves the rune at the last step of Path if Stay is set, otherwise it restores the cell under the rune.
+func (slide *SlideRune) End(screen *Screen) {
+	if len(slide.Path) == 0 {
+		return
+	}
+	slide.restore(screen)
+	if slide.Stay {
+		last := slide.Path[len(slide.Path)-1]
+		screen.DrawRune(last.X(), last.Y(), slide.Rune, slide.Style)
+	}
+}
+
+// restore redraws the cell the rune was drawn over.
+func (slide *SlideRune) restore(screen *Screen) {
+	if !slide.drawn {
+		return
+	}
+	step := slide.Path[slide.index]
+	screen.DrawRune(step.X(), step.Y(), slide.under.PendingRune, slide.under.PendingStyle)
+	slide.drawn = false
+}
+
+// Shake randomly offsets the contents of a region by up to Magnitude cells for Duration, then restores it. Offsets are chosen from a source seeded with Seed so that shakes can be reproduced. A negative Width, Height, or Magnitude is treated as 0.
+type Shake struct {
+	X, Y, Width, Height int
+	Magnitude           int
+	Duration            time.Duration
+	Seed                int64
+
+	rand     *rand.Rand
+	original [][]Cell
+}
+
+// Step draws the region's original contents at a new random offset.
+func (shake *Shake) Step(screen *Screen, elapsed time.Duration) bool {
+	if shake.original == nil {
+		if shake.Width < 0 {
+			shake.Width = 0
+		}
+		if shake.Height < 0 {
+			shake.Height = 0
+		}
+		if shake.Magnitude < 0 {
+			shake.Magnitude = 0
+		}
+		shake.rand = rand.New(rand.NewSource(shake.Seed))
+		shake.original = make([][]Cell, shake.Height)
+		for y := range shake.original {
+			shake.original[y] = make([]Cell, shake.Width)
+			for x := range shake.original[y] {
+				shake.original[y][x], _ = screen.pendingCell(shake.X+x, shake.Y+y)
+			}
+		}
+	}
+	if elapsed >= shake.Duration {
+		return true
+	}
+	offsetX, offsetY := 0, 0
+	if shake.Magnitude > 0 {
+		offsetX = shake.rand.Intn(shake.Magnitude*2+1) - shake.Magnitude
+		offsetY = shake.rand.Intn(shake.Magnitude*2+1) - shake.Magnitude
+	}
+	for y := 0; y < shake.Height; y++ {
+		for x := 0; x < shake.Width; x++ {
+			sourceX, sourceY := x-offsetX, y-offsetY
+			if sourceX < 0 || sourceY < 0 || sourceX >= shake.Width || sourceY >= shake.Height {
+				screen.DrawRune(shake.X+x, shake.Y+y, ' ', Style{})
+				continue
+			}
+			cell := shake.original[sourceY][sourceX]
+			screen.DrawRune(shake.X+x, shake.Y+y, cell.PendingRune, cell.PendingStyle)
+		}
+	}
+	return false
+}
+
+// End restores the region's original contents.
+func (shake *Shake) End(screen *Screen) {
+	for y := range shake.original {
+		for x, cell := range shake.original[y] {
+			screen.DrawRune(shake.X+x, shake.Y+y, cell.PendingRune, cell.PendingStyle)
+		}
+	}
+	shake.original = nil
+}
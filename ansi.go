@@ -0,0 +1,219 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ColorMode is the color depth used when writing Styles as ANSI escape sequences.
+type ColorMode uint8
+
+// These are our supported color modes, from the most colors to the fewest.
+const (
+	ColorModeTrueColor ColorMode = iota
+	ColorMode256
+	ColorMode16
+	ColorMode8
+)
+
+// ansiEncoder converts cells into ANSI escape sequences, only emitting cursor movement and style changes when they are needed.
+type ansiEncoder struct {
+	mode             ColorMode
+	dither           bool
+	buf              []byte
+	style            Style
+	styled           bool
+	cursorX, cursorY int
+}
+
+// reset forgets the encoder's known cursor position and style.
+func (enc *ansiEncoder) reset() {
+	enc.styled = false
+	enc.cursorX, enc.cursorY = -1, -1
+}
+
+// clearScreen appends the sequence for clearing the terminal and homing the cursor.
+func (enc *ansiEncoder) clearScreen() {
+	enc.buf = append(enc.buf, "\x1b[0m\x1b[2J\x1b[H"...)
+	enc.styled = false
+	enc.cursorX, enc.cursorY = 0, 0
+}
+
+// scrollRows appends the sequences for moving the rows from top to bottom, inclusive, down by n rows, or up if n is negative. Rows scrolled in are blank.
+func (enc *ansiEncoder) scrollRows(top, bottom, n int) {
+	// Setting the scroll region resets the style and homes the cursor.
+	enc.buf = append(enc.buf, "\x1b[0m\x1b["...)
+	enc.buf = strconv.AppendInt(enc.buf, int64(top+1), 10)
+	enc.buf = append(enc.buf, ';')
+	enc.buf = strconv.AppendInt(enc.buf, int64(bottom+1), 10)
+	enc.buf = append(enc.buf, "r\x1b["...)
+	if n > 0 {
+		enc.buf = strconv.AppendInt(enc.buf, int64(n), 10)
+		enc.buf = append(enc.buf, 'T')
+	} else {
+		enc.buf = strconv.AppendInt(enc.buf, int64(-n), 10)
+		enc.buf = append(enc.buf, 'S')
+	}
+	enc.buf = append(enc.buf, "\x1b[r"...)
+	enc.styled = false
+	enc.cursorX, enc.cursorY = -1, -1
+}
+
+// moveTo appends a cursor movement to x and y if the cursor is not already there.
+func (enc *ansiEncoder) moveTo(x, y int) {
+	if enc.cursorX == x && enc.cursorY == y {
+		return
+	}
+	enc.buf = append(enc.buf, "\x1b["...)
+	enc.buf = strconv.AppendInt(enc.buf, int64(y+1), 10)
+	enc.buf = append(enc.buf, ';')
+	enc.buf = strconv.AppendInt(enc.buf, int64(x+1), 10)
+	enc.buf = append(enc.buf, 'H')
+	enc.cursorX, enc.cursorY = x, y
+}
+
+// setStyle appends the SGR sequence for s if it differs from the current style.
+func (enc *ansiEncoder) setStyle(s Style) {
+	if enc.dither && enc.mode != ColorModeTrueColor && s.Background != ColorNone {
+		s.Background = ditherColor(s.Background, enc.mode, enc.cursorX, enc.cursorY)
+	}
+	if enc.styled && enc.style == s {
+		return
+	}
+	enc.buf = append(enc.buf, "\x1b[0"...)
+	if s.Bold {
+		enc.buf = append(enc.buf, ";1"...)
+	}
+	if s.Dim {
+		enc.buf = append(enc.buf, ";2"...)
+	}
+	if s.Underline {
+		enc.buf = append(enc.buf, ";4"...)
+	}
+	if s.Blink {
+		enc.buf = append(enc.buf, ";5"...)
+	}
+	if s.Reverse {
+		enc.buf = append(enc.buf, ";7"...)
+	}
+	enc.appendColor(s.Foreground, 38)
+	enc.appendColor(s.Background, 48)
+	enc.buf = append(enc.buf, 'm')
+	enc.style = s
+	enc.styled = true
+}
+
+// appendColor appends the SGR parameters for c, using base 38 for foregrounds and 48 for backgrounds. ColorNone leaves the terminal default in place.
+func (enc *ansiEncoder) appendColor(c Color, base int) {
+	if c == ColorNone {
+		return
+	}
+	enc.buf = append(enc.buf, ';')
+	switch enc.mode {
+	case ColorMode256:
+		enc.buf = strconv.AppendInt(enc.buf, int64(base), 10)
+		enc.buf = append(enc.buf, ";5;"...)
+		enc.buf = strconv.AppendInt(enc.buf, int64(quantizeColor(c, enc.mode)), 10)
+	case ColorMode16, ColorMode8:
+		// 38 and 48 become 30 and 40 for the normal colors, or 90 and 100 for the bright ones.
+		index := quantizeColor(c, enc.mode)
+		code := base - 8 + index
+		if index >= 8 {
+			code = base + 52 + index - 8
+		}
+		enc.buf = strconv.AppendInt(enc.buf, int64(code), 10)
+	default:
+		enc.buf = strconv.AppendInt(enc.buf, int64(base), 10)
+		enc.buf = append(enc.buf, ";2;"...)
+		enc.buf = strconv.AppendInt(enc.buf, int64(c.R), 10)
+		enc.buf = append(enc.buf, ';')
+		enc.buf = strconv.AppendInt(enc.buf, int64(c.G), 10)
+		enc.buf = append(enc.buf, ';')
+		enc.buf = strconv.AppendInt(enc.buf, int64(c.B), 10)
+	}
+}
+
+// putRune appends the rune at the current cursor position, drawing an empty rune or a control character as a space.
+func (enc *ansiEncoder) putRune(r rune, s Style) {
+	enc.setStyle(s)
+	if isControl(r) {
+		r = ' '
+	}
+	enc.buf = append(enc.buf, string(r)...)
+	enc.cursorX++
+}
+
+// encodeFrame appends the cells of frame, positioning the cursor as needed.
+func (enc *ansiEncoder) encodeFrame(frame Frame) {
+	for _, cell := range frame.Cells {
+		enc.moveTo(cell.X, cell.Y)
+		enc.putRune(cell.Rune, cell.Style)
+	}
+	if enc.styled {
+		enc.buf = append(enc.buf, "\x1b[0m"...)
+		enc.styled = false
+	}
+}
+
+// WriteANSI writes the Screen's committed cells to w as lines of text with ANSI escape sequences for styling.
+func (screen *Screen) WriteANSI(w io.Writer) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+
+	bw := bufio.NewWriter(w)
+	enc := ansiEncoder{}
+	enc.reset()
+	for y := range screen.cells {
+		for x := range screen.cells[y] {
+			enc.putRune(screen.cells[y][x].Rune, screen.cells[y][x].Style)
+		}
+		enc.buf = append(enc.buf, "\x1b[0m\n"...)
+		enc.styled = false
+		if _, err := bw.Write(enc.buf); err != nil {
+			return err
+		}
+		enc.buf = enc.buf[:0]
+	}
+	return bw.Flush()
+}
+
+// isControl returns whether r is a C0 or C1 control character, which terminals act on rather than display.
+func isControl(r rune) bool {
+	return r < 0x20 || (r >= 0x7f && r <= 0x9f)
+}
+
+// stripControl returns s without control characters, so that it can be placed within an escape sequence without ending it early.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// ansiPalette is the standard 16 color terminal palette, in ANSI order.
+var ansiPalette = [16]Color{
+	Color0, Color1, Color2, Color3, Color4, Color5, Color6, Color7,
+	Color8, Color9, Color10, Color11, Color12, Color13, Color14, Color15,
+}
@@ -0,0 +1,98 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrAppNotInitialized is returned when the package-level functions are used before Init.
+var ErrAppNotInitialized = errors.New("goro has not been initialized")
+
+// App holds a Backend and the context of the logic running against it. Unlike the package-level functions, any number of Apps may exist in a single process.
+type App struct {
+	backend Backend
+	ctx     context.Context
+	cancel  context.CancelFunc
+	err     error
+	mutex   sync.Mutex
+}
+
+// NewApp initializes the given Backend and returns an App that uses it.
+func NewApp(backend Backend) (*App, error) {
+	if err := backend.Init(); err != nil {
+		return nil, err
+	}
+	app := &App{
+		backend: backend,
+	}
+	app.ctx, app.cancel = context.WithCancel(context.Background())
+	return app, nil
+}
+
+// Backend returns the App's Backend.
+func (app *App) Backend() Backend {
+	return app.backend
+}
+
+// Screens returns the Screens displayed by the App's Backend.
+func (app *App) Screens() *Screens {
+	return app.backend.Screens()
+}
+
+// Context returns the App's context, which is canceled when the App quits.
+func (app *App) Context() context.Context {
+	return app.ctx
+}
+
+// Setup takes a callback for setting up the App before the backend creates any windows. Optional.
+func (app *App) Setup(cb func(*Screen)) error {
+	return app.backend.Setup(cb)
+}
+
+// Run runs the Backend with the provided logic callback until the App quits. It returns the error given to QuitWithError, or the Backend's error if it stopped on its own.
+func (app *App) Run(cb func(*Screen)) error {
+	err := app.backend.Run(app.ctx, cb)
+	app.cancel()
+	app.backend.Quit()
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.err != nil {
+		return app.err
+	}
+	return err
+}
+
+// Quit stops the App, causing Run to return nil. Screens waiting for events receive EventQuit.
+func (app *App) Quit() {
+	app.QuitWithError(nil)
+}
+
+// QuitWithError stops the App, causing Run to return err.
+func (app *App) QuitWithError(err error) {
+	app.mutex.Lock()
+	if app.err == nil {
+		app.err = err
+	}
+	app.mutex.Unlock()
+	app.cancel()
+}
@@ -19,14 +19,16 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package goro
 
 import (
+	"context"
+
 	"github.com/kettek/goro/glyphs"
 )
 
-// Backend is an interface through which a Screen is displayed and controlled.
+// Backend is an interface through which a Screen is displayed and controlled. Run must return once its context is done, and Quit must be safe to call more than once.
 type Backend interface {
 	Init() error
 	Setup(func(*Screen)) error
-	Run(func(*Screen)) error
+	Run(context.Context, func(*Screen)) error
 	Refresh()
 	Quit()
 	Size() (int, int)
@@ -37,4 +39,5 @@ type Backend interface {
 	SetTitle(string)
 	SetGlyphs(glyphs.ID, string, float64) error
 	SyncSize()
+	Screens() *Screens
 }
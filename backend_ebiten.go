@@ -21,8 +21,12 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 */
 
 import (
+	"context"
+	"errors"
+	"image"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/text"
@@ -33,7 +37,9 @@ import (
 // BackendEbiten is our Ebiten backend.
 type BackendEbiten struct {
 	screen                Screen
+	screens               Screens
 	imageBuffer           *ebiten.Image
+	scrollBuffer          *ebiten.Image
 	op                    *ebiten.DrawImageOptions
 	title                 string
 	width, height         int
@@ -65,9 +71,11 @@ func (backend *BackendEbiten) Init() error {
 	backend.glyphs = make([]glyphs.Glyphs, 10)
 	backend.emptyCell, _ = ebiten.NewImage(16, 16, ebiten.FilterDefault)
 
-	if err := backend.screen.Init(); err != nil {
+	if err := backend.screen.Init(backend); err != nil {
 		return err
 	}
+	backend.screens.backend = backend
+	backend.screens.Add(&backend.screen)
 
 	backend.refreshChan = make(chan struct{})
 
@@ -96,9 +104,16 @@ func (backend *BackendEbiten) Setup(cb func(*Screen)) (err error) {
 	return nil
 }
 
-// Run runs the given function cb as a goroutine.
-func (backend *BackendEbiten) Run(cb func(*Screen)) (err error) {
+// errEbitenQuit is returned from the update function to stop ebiten once Run's context is done.
+var errEbitenQuit = errors.New("ebiten quit")
+
+// Run runs the given function cb as a goroutine, returning once ctx is done or the window is closed.
+func (backend *BackendEbiten) Run(ctx context.Context, cb func(*Screen)) (err error) {
+	backend.screens.setContext(ctx)
 	err = ebiten.Run(func(screenBuffer *ebiten.Image) (err error) {
+		if ctx.Err() != nil {
+			return errEbitenQuit
+		}
 		if !backend.hasStarted {
 			backend.hasStarted = true
 			backend.SyncSize()
@@ -109,6 +124,8 @@ func (backend *BackendEbiten) Run(cb func(*Screen)) (err error) {
 			}()
 		}
 
+		backend.screens.advanceTicks(time.Now())
+
 		keyEvents := make([]EventKey, 0)
 		// ... Ew.
 		for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
@@ -150,17 +167,19 @@ func (backend *BackendEbiten) Run(cb func(*Screen)) (err error) {
 			})
 		}
 		// Send our KeyEvents
-		if backend.screen.UseKeys {
-			for _, k := range keyEvents {
-				backend.screen.eventChan <- k
-			}
+		for _, k := range keyEvents {
+			backend.screens.sendKey(k)
 		}
 
 		// ... Ew x2.
+		mouseX, mouseY := ebiten.CursorPosition()
+		if backend.cellWidth > 0 && backend.cellHeight > 0 {
+			mouseX, mouseY = mouseX/backend.cellWidth, mouseY/backend.cellHeight
+		}
 		for m := ebiten.MouseButtonLeft; m <= ebiten.MouseButtonMiddle; m++ {
 			if ebiten.IsMouseButtonPressed(m) {
-				if !backend.pressedMouse[m] && backend.screen.UseMouse {
-					backend.screen.eventChan <- Event(EventMouse{})
+				if !backend.pressedMouse[m] {
+					backend.screens.sendMouse(EventMouse{X: mouseX, Y: mouseY, Button: int(m), State: true})
 				}
 				backend.pressedMouse[m] = true
 			} else {
@@ -170,10 +189,14 @@ func (backend *BackendEbiten) Run(cb func(*Screen)) (err error) {
 
 		// Draw
 		if !ebiten.IsDrawingSkipped() {
-			if backend.screen.Redraw {
-				backend.drawCellBackgrounds(backend.imageBuffer)
-				backend.drawCellForegrounds(backend.imageBuffer)
-				backend.screen.Redraw = false
+			var cells []ebitenCell
+			backend.screens.scroll(backend.scroll)
+			backend.screens.redraw(func() { backend.imageBuffer.Clear() }, func(x, y int, screen *Screen, cell *Cell) {
+				cells = append(cells, ebitenCell{x: x, y: y, screen: screen, cell: *cell})
+			})
+			if len(cells) > 0 {
+				backend.drawCellBackgrounds(backend.imageBuffer, cells)
+				backend.drawCellForegrounds(backend.imageBuffer, cells)
 			}
 
 			backend.op.GeoM.Reset()
@@ -183,6 +206,9 @@ func (backend *BackendEbiten) Run(cb func(*Screen)) (err error) {
 		return nil
 	}, backend.width, backend.height, 1, backend.title)
 
+	if err == errEbitenQuit {
+		return nil
+	}
 	return err
 }
 
@@ -264,6 +290,17 @@ func (backend *BackendEbiten) SetGlyphsFromTTFBytes(id glyphs.ID, bytes []byte,
 	return nil
 }
 
+// Rasterizer returns a Rasterizer that uses the backend's glyphs, allowing screenshots to be taken of the screen.
+func (backend *BackendEbiten) Rasterizer() *Rasterizer {
+	rasterizer := newRasterizer()
+	for id, g := range backend.glyphs {
+		if g != nil {
+			rasterizer.SetGlyphs(glyphs.ID(id), g)
+		}
+	}
+	return rasterizer
+}
+
 // syncGlyphs synchronizes the screen's size and backend size, along with associated cached variables, to use the updated glyphs.
 func (backend *BackendEbiten) syncGlyphs(id glyphs.ID) {
 	backend.cellWidth = backend.glyphs[id].Width()
@@ -274,6 +311,11 @@ func (backend *BackendEbiten) syncGlyphs(id glyphs.ID) {
 	backend.SyncSize()
 }
 
+// Screens returns the collection of Screens drawn by the backend.
+func (backend *BackendEbiten) Screens() *Screens {
+	return &backend.screens
+}
+
 // SyncSize is the external call to synchronize the screen's size.
 func (backend *BackendEbiten) SyncSize() {
 	c, r := backend.screen.Size()
@@ -285,64 +327,82 @@ func (backend *BackendEbiten) SyncSize() {
 	if backend.hasStarted {
 		backend.emptyCell, _ = ebiten.NewImage(backend.cellWidth, backend.cellHeight, ebiten.FilterDefault)
 		backend.imageBuffer, _ = ebiten.NewImage(backend.width, backend.height, ebiten.FilterDefault)
+		backend.screens.damage()
 	}
 
 	backend.Refresh()
 }
 
-// drawCellForegrounds draws the colored glyphs for the cell at x and y.
-func (backend *BackendEbiten) drawCellForegrounds(target *ebiten.Image) {
-	backend.screen.cellsMutex.Lock()
-	for y := 0; y < len(backend.screen.cells); y++ {
-		for x := 0; x < len(backend.screen.cells[y]); x++ {
-			if !backend.screen.cells[y][x].Redraw {
-				continue
-			}
-			fg := backend.screen.cells[y][x].Style.Foreground
-			if fg == ColorNone {
-				fg = backend.screen.Foreground
-			}
-			// Draw our rune
-			if backend.screen.cells[y][x].Rune != rune(0) {
-				glyphSet := backend.glyphs[backend.screen.cells[y][x].Glyphs]
-				switch glyphSet := glyphSet.(type) {
-				case *glyphs.Truetype:
-					bounds, _, _ := glyphSet.Normal.GlyphBounds(backend.screen.cells[y][x].Rune)
-					text.Draw(
-						target,
-						string(backend.screen.cells[y][x].Rune),
-						glyphSet.Normal,
-						x*glyphSet.Width()+(glyphSet.Width()/2-bounds.Max.X.Round()/2),
-						y*glyphSet.Height()+glyphSet.Ascent(),
-						fg,
-					)
-				}
+// scroll moves a region of the image buffer by copying it through the scroll buffer, as an image cannot be drawn onto itself.
+func (backend *BackendEbiten) scroll(rect Rect, dx, dy int) bool {
+	// The cells that move are those whose source is also within rect.
+	moved := rect.Intersect(Rect{rect.X + dx, rect.Y + dy, rect.Width, rect.Height})
+	if moved.Empty() || backend.cellWidth == 0 || backend.cellHeight == 0 {
+		return false
+	}
+	cw, ch := backend.cellWidth, backend.cellHeight
+	source := image.Rect((moved.X-dx)*cw, (moved.Y-dy)*ch, (moved.X-dx+moved.Width)*cw, (moved.Y-dy+moved.Height)*ch)
+
+	width, height := backend.imageBuffer.Size()
+	if backend.scrollBuffer == nil {
+		backend.scrollBuffer, _ = ebiten.NewImage(width, height, ebiten.FilterDefault)
+	} else if w, h := backend.scrollBuffer.Size(); w != width || h != height {
+		backend.scrollBuffer.Dispose()
+		backend.scrollBuffer, _ = ebiten.NewImage(width, height, ebiten.FilterDefault)
+	}
+
+	op := &ebiten.DrawImageOptions{CompositeMode: ebiten.CompositeModeCopy}
+	backend.scrollBuffer.DrawImage(backend.imageBuffer.SubImage(source).(*ebiten.Image), op)
+	op.GeoM.Translate(float64(moved.X*cw), float64(moved.Y*ch))
+	backend.imageBuffer.DrawImage(backend.scrollBuffer.SubImage(image.Rect(0, 0, source.Dx(), source.Dy())).(*ebiten.Image), op)
+	return true
+}
+
+// ebitenCell is a copy of a cell to be drawn at x and y in the backend.
+type ebitenCell struct {
+	x, y   int
+	screen *Screen
+	cell   Cell
+}
+
+// drawCellForegrounds draws the colored glyphs for the given cells.
+func (backend *BackendEbiten) drawCellForegrounds(target *ebiten.Image, cells []ebitenCell) {
+	for _, c := range cells {
+		fg := c.cell.Style.Foreground
+		if fg == ColorNone {
+			fg = c.screen.Foreground
+		}
+		// Draw our rune
+		if c.cell.Rune != rune(0) {
+			glyphSet := backend.glyphs[c.cell.Glyphs]
+			switch glyphSet := glyphSet.(type) {
+			case *glyphs.Truetype:
+				bounds, _, _ := glyphSet.Normal.GlyphBounds(c.cell.Rune)
+				text.Draw(
+					target,
+					string(c.cell.Rune),
+					glyphSet.Normal,
+					c.x*glyphSet.Width()+(glyphSet.Width()/2-bounds.Max.X.Round()/2)+int(c.cell.OffsetX*float64(glyphSet.Width())),
+					c.y*glyphSet.Height()+glyphSet.Ascent()+int(c.cell.OffsetY*float64(glyphSet.Height())),
+					fg,
+				)
 			}
-			backend.screen.cells[y][x].Redraw = false
 		}
 	}
-	backend.screen.cellsMutex.Unlock()
 }
 
-// drawCellBackgrounds draws the background at the cell at x and y
-func (backend *BackendEbiten) drawCellBackgrounds(target *ebiten.Image) {
-	backend.screen.cellsMutex.Lock()
-	for y := 0; y < len(backend.screen.cells); y++ {
-		for x := 0; x < len(backend.screen.cells[y]); x++ {
-			if !backend.screen.cells[y][x].Redraw {
-				continue
-			}
-			bg := backend.screen.cells[y][x].Style.Background
-			if bg == ColorNone {
-				bg = backend.screen.Background
-			}
-			backend.emptyCell.Fill(bg)
-			backend.op.GeoM.Reset()
-			backend.op.GeoM.Translate(float64(x*backend.cellWidth), float64(y*backend.cellHeight))
-			target.DrawImage(backend.emptyCell, backend.op)
+// drawCellBackgrounds draws the backgrounds for the given cells.
+func (backend *BackendEbiten) drawCellBackgrounds(target *ebiten.Image, cells []ebitenCell) {
+	for _, c := range cells {
+		bg := c.cell.Style.Background
+		if bg == ColorNone {
+			bg = c.screen.Background
 		}
+		backend.emptyCell.Fill(bg)
+		backend.op.GeoM.Reset()
+		backend.op.GeoM.Translate(float64(c.x*backend.cellWidth), float64(c.y*backend.cellHeight))
+		target.DrawImage(backend.emptyCell, backend.op)
 	}
-	backend.screen.cellsMutex.Unlock()
 }
 
 func (backend *BackendEbiten) DrawRect(image *ebiten.Image, x0, y0, x1, y1 float32, c Color) {
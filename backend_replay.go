@@ -0,0 +1,208 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"time"
+
+	"github.com/kettek/goro/glyphs"
+)
+
+// BackendReplay is a backend that feeds the events of an EventLog to its Screen in place of live input. If Display is set, the Screen is drawn through it, otherwise the replay runs headless.
+type BackendReplay struct {
+	Log           *EventLog
+	Speed         float64 // Playback speed multiplier. 0 or less replays as fast as possible.
+	Display       Backend
+	Columns, Rows int // Size of the headless Screen. Defaults to 80x24.
+	screen        Screen
+	screens       Screens
+	title         string
+}
+
+// InitReplay initializes the replay backend to replay log at the given speed, optionally drawing through display. Calls BackendReplay.Init().
+func InitReplay(log *EventLog, speed float64, display Backend) error {
+	return Init(Backend(&BackendReplay{Log: log, Speed: speed, Display: display}))
+}
+
+// Init sets the random seed to the log's seed and initializes the Display, if any.
+func (backend *BackendReplay) Init() error {
+	if backend.Log == nil {
+		backend.Log = &EventLog{}
+	}
+	SetSeed(backend.Log.Seed)
+	backend.title = "goro - Replay"
+
+	if backend.Display != nil {
+		return backend.Display.Init()
+	}
+	if backend.Columns <= 0 || backend.Rows <= 0 {
+		backend.Columns, backend.Rows = 80, 24
+	}
+	if err := backend.screen.Init(backend); err != nil {
+		return err
+	}
+	backend.screens.backend = backend
+	backend.screens.Add(&backend.screen)
+	return nil
+}
+
+// Quit quits the Display or closes the headless Screen.
+func (backend *BackendReplay) Quit() {
+	if backend.Display != nil {
+		backend.Display.Quit()
+		return
+	}
+	backend.screen.Close()
+}
+
+// Setup runs the given function cb, ignoring live input and ticks to the Screen from then on.
+func (backend *BackendReplay) Setup(cb func(*Screen)) (err error) {
+	if backend.Display != nil {
+		return backend.Display.Setup(func(screen *Screen) {
+			screen.setReplaying(true)
+			cb(screen)
+		})
+	}
+	backend.screen.setReplaying(true)
+	cb(&backend.screen)
+	return nil
+}
+
+// Run runs the given function cb as a goroutine and replays the log to its Screen, returning once ctx is done. After the last event an EventQuit is sent.
+func (backend *BackendReplay) Run(ctx context.Context, cb func(*Screen)) (err error) {
+	if backend.Display != nil {
+		return backend.Display.Run(ctx, func(screen *Screen) {
+			screen.setReplaying(true)
+			go backend.play(ctx, screen)
+			cb(screen)
+		})
+	}
+	backend.screens.setContext(ctx)
+	backend.screen.setReplaying(true)
+	go cb(&backend.screen)
+	backend.play(ctx, &backend.screen)
+	<-ctx.Done()
+	return nil
+}
+
+// play sends each logged event to screen, paced by Speed.
+func (backend *BackendReplay) play(ctx context.Context, screen *Screen) {
+	start := time.Now()
+	for _, logged := range backend.Log.Events {
+		if backend.Speed > 0 {
+			wait := time.Until(start.Add(time.Duration(float64(logged.Time) / backend.Speed)))
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}
+		if resize, ok := logged.Event.(EventResize); ok && screen.AutoSize {
+			screen.SetSize(resize.Columns, resize.Rows)
+		}
+		if !screen.pushReplayed(logged.Event) {
+			return
+		}
+	}
+	screen.pushReplayed(EventQuit{})
+}
+
+// Refresh forces the Display to redraw. Does nothing when headless.
+func (backend *BackendReplay) Refresh() {
+	if backend.Display != nil {
+		backend.Display.Refresh()
+	}
+}
+
+// Size returns the Display's size or the headless Columns and Rows.
+func (backend *BackendReplay) Size() (int, int) {
+	if backend.Display != nil {
+		return backend.Display.Size()
+	}
+	return backend.Columns, backend.Rows
+}
+
+// SetSize sets the Display's size or the headless Columns and Rows.
+func (backend *BackendReplay) SetSize(w, h int) {
+	if backend.Display != nil {
+		backend.Display.SetSize(w, h)
+		return
+	}
+	backend.Columns, backend.Rows = w, h
+}
+
+// Units returns the unit type the backend uses for Size().
+func (backend *BackendReplay) Units() int {
+	if backend.Display != nil {
+		return backend.Display.Units()
+	}
+	return UnitCells
+}
+
+// Scale returns the Display's scaling.
+func (backend *BackendReplay) Scale() float64 {
+	if backend.Display != nil {
+		return backend.Display.Scale()
+	}
+	return 1
+}
+
+// SetScale sets the Display's scaling.
+func (backend *BackendReplay) SetScale(scale float64) {
+	if backend.Display != nil {
+		backend.Display.SetScale(scale)
+	}
+}
+
+// SetTitle sets the Display's title.
+func (backend *BackendReplay) SetTitle(title string) {
+	if backend.Display != nil {
+		backend.Display.SetTitle(title)
+		return
+	}
+	backend.title = title
+}
+
+// SetGlyphs sets the Display's glyphs.
+func (backend *BackendReplay) SetGlyphs(id glyphs.ID, path string, size float64) error {
+	if backend.Display != nil {
+		return backend.Display.SetGlyphs(id, path, size)
+	}
+	return nil
+}
+
+// SyncSize synchronizes the Display's size.
+func (backend *BackendReplay) SyncSize() {
+	if backend.Display != nil {
+		backend.Display.SyncSize()
+	}
+}
+
+// Screens returns the Display's Screens or the headless Screens.
+func (backend *BackendReplay) Screens() *Screens {
+	if backend.Display != nil {
+		return backend.Display.Screens()
+	}
+	return &backend.screens
+}
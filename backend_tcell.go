@@ -21,17 +21,25 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 */
 
 import (
+	"context"
+	"sync"
+
 	"github.com/gdamore/tcell"
 	"github.com/kettek/goro/glyphs"
 )
 
-// BackendTCell is the backend for the tcell library.
+// BackendTCell is the backend for the tcell library. Colors are reduced to the palette of the terminal, or of ColorMode if it has fewer colors. If Dither is set, backgrounds use ordered dithering when reduced.
 type BackendTCell struct {
+	ColorMode   ColorMode
+	Dither      bool
+	colorMode   ColorMode
 	screen      Screen
+	screens     Screens
 	tcellScreen tcell.Screen
 	refreshChan chan struct{}
 	hasStarted  bool
 	title       string
+	quitOnce    sync.Once
 }
 
 // InitTCell initializes the TCell backend for use. Calls BackendTCell.Init().
@@ -50,23 +58,32 @@ func (backend *BackendTCell) Init() (err error) {
 		return err
 	}
 
+	backend.colorMode = colorModeFor(backend.tcellScreen.Colors())
+	if backend.ColorMode > backend.colorMode {
+		backend.colorMode = backend.ColorMode
+	}
+
 	backend.tcellScreen.EnableMouse()
 	backend.tcellScreen.SetStyle(tcell.StyleDefault)
 	backend.tcellScreen.Clear()
 
-	if err := backend.screen.Init(); err != nil {
+	if err := backend.screen.Init(backend); err != nil {
 		return err
 	}
+	backend.screens.backend = backend
+	backend.screens.Add(&backend.screen)
 
 	backend.refreshChan = make(chan struct{})
 
 	return nil
 }
 
-// Quit causes our screen to close.
+// Quit causes our screen to close and restores the terminal.
 func (backend *BackendTCell) Quit() {
-	backend.screen.Close()
-	backend.tcellScreen.Fini()
+	backend.quitOnce.Do(func() {
+		backend.screen.Close()
+		backend.tcellScreen.Fini()
+	})
 }
 
 // Setup runs the given function cb.
@@ -75,8 +92,10 @@ func (backend *BackendTCell) Setup(cb func(*Screen)) (err error) {
 	return nil
 }
 
-// Run runs the given function cb as a goroutine and starts the entire tcell loop.
-func (backend *BackendTCell) Run(cb func(*Screen)) (err error) {
+// Run runs the given function cb as a goroutine and starts the entire tcell loop, returning once ctx is done.
+func (backend *BackendTCell) Run(ctx context.Context, cb func(*Screen)) (err error) {
+	backend.screens.setContext(ctx)
+	go backend.screens.runTicks(ctx)
 	go func() {
 		cb(&backend.screen)
 	}()
@@ -84,8 +103,14 @@ func (backend *BackendTCell) Run(cb func(*Screen)) (err error) {
 	// I guess this is okay to do.
 	go func() {
 		for {
-			<-backend.refreshChan
-			backend.draw()
+			select {
+			case <-backend.refreshChan:
+				backend.draw()
+			case <-ctx.Done():
+				// Wake up PollEvent so that the loop below can return.
+				backend.tcellScreen.PostEvent(tcell.NewEventInterrupt(nil))
+				return
+			}
 		}
 	}()
 
@@ -93,27 +118,20 @@ func (backend *BackendTCell) Run(cb func(*Screen)) (err error) {
   backend.SetTitle(backend.title)
 	for {
 		event := backend.tcellScreen.PollEvent()
+		if ctx.Err() != nil || event == nil {
+			return nil
+		}
 		switch event := event.(type) {
 		case *tcell.EventResize:
 			w, h := event.Size()
-			if backend.screen.AutoSize {
-				backend.screen.SetSize(w, h)
-			}
 			backend.tcellScreen.Sync()
-			backend.screen.eventChan <- Event(EventResize{
-				Columns: w,
-				Rows:    h,
-			})
+			backend.screens.sendResize(w, h)
 		case *tcell.EventKey:
-			if backend.screen.UseKeys {
-				backend.screen.eventChan <- Event(backend.tCellEventKeyToEventKey(event))
-			}
+			backend.screens.sendKey(backend.tCellEventKeyToEventKey(event))
 		case *tcell.EventMouse:
-			if backend.screen.UseMouse {
-				x, y := event.Position()
-				//button := event.Buttons()
-				backend.screen.eventChan <- Event(EventMouse{X: x, Y: y})
-			}
+			x, y := event.Position()
+			buttons := event.Buttons()
+			backend.screens.sendMouse(EventMouse{X: x, Y: y, State: buttons&(tcell.Button1|tcell.Button2|tcell.Button3) != 0})
 		}
 		backend.draw()
 	}
@@ -121,7 +139,10 @@ func (backend *BackendTCell) Run(cb func(*Screen)) (err error) {
 
 // Refresh causes the backend loop to redraw the screen.
 func (backend *BackendTCell) Refresh() {
-	backend.refreshChan <- struct{}{}
+	select {
+	case backend.refreshChan <- struct{}{}:
+	case <-backend.screen.Context().Done():
+	}
 }
 
 // Size returns the current backend window dimensions.
@@ -147,21 +168,16 @@ func (backend *BackendTCell) Scale() float64 {
 func (backend *BackendTCell) SetScale(scale float64) {
 }
 
-// draw is used for drawing the screen's cells to the tcell screen.
+// Screens returns the collection of Screens drawn by the backend.
+func (backend *BackendTCell) Screens() *Screens {
+	return &backend.screens
+}
+
+// draw is used for drawing the screens' cells to the tcell screen.
 func (backend *BackendTCell) draw() {
-	backend.screen.cellsMutex.Lock()
-	defer backend.screen.cellsMutex.Unlock()
-	if backend.screen.Redraw {
-		for y := 0; y < len(backend.screen.cells); y++ {
-			for x := 0; x < len(backend.screen.cells[y]); x++ {
-				if backend.screen.cells[y][x].Redraw {
-					backend.tcellScreen.SetContent(x, y, backend.screen.cells[y][x].Rune, nil, StyleToTCellStyle(backend.screen.cells[y][x].Style))
-					backend.screen.cells[y][x].Redraw = false
-				}
-			}
-		}
-		backend.screen.Redraw = false
-	}
+	backend.screens.redraw(backend.tcellScreen.Clear, func(x, y int, screen *Screen, cell *Cell) {
+		backend.tcellScreen.SetContent(x, y, cell.Rune, nil, styleToTCellStyle(cell.Style, backend.colorMode, backend.Dither, x, y))
+	})
 	backend.tcellScreen.Show()
 }
 
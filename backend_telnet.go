@@ -0,0 +1,501 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/kettek/goro/glyphs"
+)
+
+// Telnet commands and options used during negotiation.
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+
+	telnetOptionEcho = 1
+	telnetOptionSGA  = 3
+	telnetOptionNAWS = 31
+)
+
+// maxTelnetCommandLength is the longest incomplete telnet command kept until more input arrives. Longer ones are discarded.
+const maxTelnetCommandLength = 512
+
+// BackendTelnet is a backend that listens for telnet connections, serving each connection its own Screen that is drawn using ANSI escape sequences. If Dither is set, backgrounds use ordered dithering when ColorMode is not ColorModeTrueColor.
+type BackendTelnet struct {
+	Address   string
+	ColorMode ColorMode
+	Dither    bool
+	listener  net.Listener
+	setupCb   func(*Screen)
+	sessions  map[*telnetSession]struct{}
+	title     string
+	quitting  bool
+	mutex     sync.Mutex
+}
+
+// InitTelnet initializes the telnet backend to listen on the provided address, such as ":2323". Calls BackendTelnet.Init().
+func InitTelnet(address string) error {
+	return Init(Backend(&BackendTelnet{Address: address}))
+}
+
+// Init starts listening on the backend's Address.
+func (backend *BackendTelnet) Init() (err error) {
+	backend.sessions = make(map[*telnetSession]struct{})
+	backend.title = "goro - Telnet"
+	backend.listener, err = net.Listen("tcp", backend.Address)
+	return err
+}
+
+// Addr returns the address the backend is listening on.
+func (backend *BackendTelnet) Addr() net.Addr {
+	return backend.listener.Addr()
+}
+
+// Quit stops listening and closes all connections.
+func (backend *BackendTelnet) Quit() {
+	backend.mutex.Lock()
+	backend.quitting = true
+	sessions := make([]*telnetSession, 0, len(backend.sessions))
+	for session := range backend.sessions {
+		sessions = append(sessions, session)
+	}
+	backend.mutex.Unlock()
+
+	backend.listener.Close()
+	for _, session := range sessions {
+		session.Quit()
+	}
+}
+
+// Setup stores the given function cb to be called with each new connection's Screen before it is passed to Run's callback.
+func (backend *BackendTelnet) Setup(cb func(*Screen)) (err error) {
+	backend.setupCb = cb
+	return nil
+}
+
+// Run accepts connections until ctx is done, running the given function cb as a goroutine for each connection's Screen.
+func (backend *BackendTelnet) Run(ctx context.Context, cb func(*Screen)) (err error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			backend.Quit()
+		case <-done:
+		}
+	}()
+
+	for {
+		conn, err := backend.listener.Accept()
+		if err != nil {
+			backend.mutex.Lock()
+			quitting := backend.quitting
+			backend.mutex.Unlock()
+			if quitting {
+				return nil
+			}
+			return err
+		}
+		go backend.serve(ctx, conn, cb)
+	}
+}
+
+// serve runs a single connection's session until it disconnects. The session's Screens use a context derived from ctx that is canceled on disconnect.
+func (backend *BackendTelnet) serve(ctx context.Context, conn net.Conn, cb func(*Screen)) {
+	session := newTelnetSession(backend, conn)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	backend.mutex.Lock()
+	backend.sessions[session] = struct{}{}
+	title := backend.title
+	backend.mutex.Unlock()
+
+	if err := session.screen.Init(session); err != nil {
+		conn.Close()
+		return
+	}
+	session.screens.backend = session
+	session.screens.Add(&session.screen)
+	session.screens.setContext(ctx)
+	session.start(title)
+	if backend.setupCb != nil {
+		backend.setupCb(&session.screen)
+	}
+	session.enableMouse(session.screen.UseMouse)
+
+	go session.drawLoop()
+	go session.screens.runTicks(ctx)
+	go cb(&session.screen)
+
+	session.readLoop()
+
+	backend.mutex.Lock()
+	delete(backend.sessions, session)
+	backend.mutex.Unlock()
+}
+
+// Refresh does nothing, as each connection is refreshed by its own Screen.
+func (backend *BackendTelnet) Refresh() {
+}
+
+// Size returns the default size of new connections' Screens.
+func (backend *BackendTelnet) Size() (int, int) {
+	return 80, 24
+}
+
+// SetSize does nothing!
+func (backend *BackendTelnet) SetSize(w, h int) {
+}
+
+// Units returns the unit type the backend uses for Size().
+func (backend *BackendTelnet) Units() int {
+	return UnitCells
+}
+
+// Scale returns the current backend window scaling. Does nothing.
+func (backend *BackendTelnet) Scale() float64 {
+	return 1
+}
+
+// SetScale sets the backend window's scaling. Does nothing.
+func (backend *BackendTelnet) SetScale(scale float64) {
+}
+
+// SetTitle sets the terminal title of all current and future connections.
+func (backend *BackendTelnet) SetTitle(title string) {
+	backend.mutex.Lock()
+	backend.title = title
+	sessions := make([]*telnetSession, 0, len(backend.sessions))
+	for session := range backend.sessions {
+		sessions = append(sessions, session)
+	}
+	backend.mutex.Unlock()
+
+	for _, session := range sessions {
+		session.SetTitle(title)
+	}
+}
+
+// SetGlyphs does nothing!
+func (backend *BackendTelnet) SetGlyphs(id glyphs.ID, path string, size float64) error {
+	return nil
+}
+
+// SyncSize does nothing!
+func (backend *BackendTelnet) SyncSize() {
+	return
+}
+
+// Screens returns nil, as each connection has its own Screens.
+func (backend *BackendTelnet) Screens() *Screens {
+	return nil
+}
+
+// telnetSession is a single telnet connection. It acts as the Backend of its Screen.
+type telnetSession struct {
+	backend       *BackendTelnet
+	conn          net.Conn
+	screen        Screen
+	screens       Screens
+	enc           ansiEncoder
+	input         terminalInput
+	command       []byte
+	columns, rows int
+	sizeMutex     sync.Mutex
+	refreshChan   chan struct{}
+	closeChan     chan struct{}
+	closeOnce     sync.Once
+	writeMutex    sync.Mutex
+}
+
+func newTelnetSession(backend *BackendTelnet, conn net.Conn) *telnetSession {
+	session := &telnetSession{
+		backend:     backend,
+		conn:        conn,
+		columns:     80,
+		rows:        24,
+		refreshChan: make(chan struct{}, 1),
+		closeChan:   make(chan struct{}),
+	}
+	session.enc.mode = backend.ColorMode
+	session.enc.dither = backend.Dither
+	session.enc.reset()
+	return session
+}
+
+// start negotiates the telnet options we need and prepares the terminal for drawing.
+func (session *telnetSession) start(title string) {
+	session.write([]byte{
+		telnetIAC, telnetWILL, telnetOptionEcho,
+		telnetIAC, telnetWILL, telnetOptionSGA,
+		telnetIAC, telnetDO, telnetOptionSGA,
+		telnetIAC, telnetDO, telnetOptionNAWS,
+	})
+	// Use the alternate screen and hide the cursor.
+	session.write([]byte("\x1b[?1049h\x1b[?25l"))
+	session.SetTitle(title)
+}
+
+// enableMouse enables or disables xterm SGR mouse reporting.
+func (session *telnetSession) enableMouse(enable bool) {
+	if enable {
+		session.write([]byte("\x1b[?1000h\x1b[?1006h"))
+	} else {
+		session.write([]byte("\x1b[?1000l\x1b[?1006l"))
+	}
+}
+
+// write writes b to the connection.
+func (session *telnetSession) write(b []byte) error {
+	session.writeMutex.Lock()
+	defer session.writeMutex.Unlock()
+	_, err := session.conn.Write(b)
+	return err
+}
+
+// readLoop reads and handles input until the connection closes, then closes the Screen.
+func (session *telnetSession) readLoop() {
+	buf := make([]byte, 1024)
+	for {
+		n, err := session.conn.Read(buf)
+		if err != nil {
+			break
+		}
+		session.handleInput(buf[:n])
+	}
+	session.Quit()
+}
+
+// handleInput strips telnet commands from data and sends the remaining terminal input to the Screen as events. Commands split across reads are kept until the rest arrives.
+func (session *telnetSession) handleInput(data []byte) {
+	if len(session.command) > 0 {
+		data = append(session.command, data...)
+		session.command = nil
+	}
+	var text []byte
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		if b != telnetIAC {
+			text = append(text, b)
+			continue
+		}
+		n := session.handleCommand(data[i:])
+		if n == 0 {
+			if len(data)-i <= maxTelnetCommandLength {
+				session.command = append([]byte(nil), data[i:]...)
+			}
+			break
+		}
+		if n < 0 {
+			text = append(text, telnetIAC)
+			n = 2
+		}
+		i += n - 1
+	}
+	for _, event := range session.input.parse(text) {
+		switch event := event.(type) {
+		case EventKey:
+			session.screens.sendKey(event)
+		case EventMouse:
+			session.screens.sendMouse(event)
+		}
+	}
+}
+
+// handleCommand handles the telnet command at the start of data, returning the number of bytes it used, 0 if the command is incomplete, or -1 for an escaped 255 byte.
+func (session *telnetSession) handleCommand(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	switch data[1] {
+	case telnetIAC:
+		return -1
+	case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+		if len(data) < 3 {
+			return 0
+		}
+		return 3
+	case telnetSB:
+		// The payload ends with IAC SE, and any 255 bytes within it are doubled.
+		var payload []byte
+		for i := 2; i+1 < len(data); i++ {
+			if data[i] != telnetIAC {
+				payload = append(payload, data[i])
+				continue
+			}
+			if data[i+1] == telnetSE {
+				session.handleSubnegotiation(payload)
+				return i + 2
+			}
+			if data[i+1] == telnetIAC {
+				payload = append(payload, telnetIAC)
+			}
+			i++
+		}
+		return 0
+	}
+	return 2
+}
+
+// handleSubnegotiation handles telnet subnegotiation, which we only use for window size updates.
+func (session *telnetSession) handleSubnegotiation(data []byte) {
+	if len(data) < 5 || data[0] != telnetOptionNAWS {
+		return
+	}
+	columns := int(data[1])<<8 | int(data[2])
+	rows := int(data[3])<<8 | int(data[4])
+	if columns <= 0 || rows <= 0 {
+		return
+	}
+	session.sizeMutex.Lock()
+	session.columns, session.rows = columns, rows
+	session.sizeMutex.Unlock()
+	session.screens.sendResize(columns, rows)
+	session.Refresh()
+}
+
+// drawLoop draws the Screen whenever it is refreshed.
+func (session *telnetSession) drawLoop() {
+	for {
+		select {
+		case <-session.refreshChan:
+			session.draw()
+		case <-session.closeChan:
+			return
+		}
+	}
+}
+
+// draw writes the Screens' cells that need redrawing to the connection.
+func (session *telnetSession) draw() {
+	session.writeMutex.Lock()
+	defer session.writeMutex.Unlock()
+
+	session.screens.scroll(session.scroll)
+	session.screens.redraw(session.enc.clearScreen, func(x, y int, screen *Screen, cell *Cell) {
+		session.enc.moveTo(x, y)
+		session.enc.putRune(cell.Rune, cell.Style)
+	})
+
+	if len(session.enc.buf) == 0 {
+		return
+	}
+	session.conn.Write(session.enc.buf)
+	session.enc.buf = session.enc.buf[:0]
+}
+
+// scroll moves a region of the client's terminal using a scroll region, which is only possible for vertical scrolls of full-width regions.
+func (session *telnetSession) scroll(rect Rect, dx, dy int) bool {
+	columns, rows := session.Size()
+	if dx != 0 || rect.X != 0 || rect.Width != columns || rect.Y+rect.Height > rows {
+		return false
+	}
+	if dy >= rect.Height || -dy >= rect.Height {
+		return false
+	}
+	session.enc.scrollRows(rect.Y, rect.Y+rect.Height-1, dy)
+	return true
+}
+
+// Init does nothing, as sessions are initialized by BackendTelnet.
+func (session *telnetSession) Init() error {
+	return nil
+}
+
+// Setup does nothing, as sessions are set up by BackendTelnet.
+func (session *telnetSession) Setup(cb func(*Screen)) error {
+	return nil
+}
+
+// Run does nothing, as sessions are run by BackendTelnet.
+func (session *telnetSession) Run(ctx context.Context, cb func(*Screen)) error {
+	return nil
+}
+
+// Refresh causes the session to redraw its Screen.
+func (session *telnetSession) Refresh() {
+	select {
+	case session.refreshChan <- struct{}{}:
+	default:
+	}
+}
+
+// Quit restores the terminal and closes the connection.
+func (session *telnetSession) Quit() {
+	session.closeOnce.Do(func() {
+		session.screen.Close()
+		session.write([]byte("\x1b[0m\x1b[?1000l\x1b[?1006l\x1b[?25h\x1b[?1049l"))
+		close(session.closeChan)
+		session.conn.Close()
+	})
+}
+
+// Size returns the size of the client's terminal.
+func (session *telnetSession) Size() (int, int) {
+	session.sizeMutex.Lock()
+	defer session.sizeMutex.Unlock()
+	return session.columns, session.rows
+}
+
+// SetSize does nothing!
+func (session *telnetSession) SetSize(w, h int) {
+}
+
+// Units returns the unit type the session uses for Size().
+func (session *telnetSession) Units() int {
+	return UnitCells
+}
+
+// Scale does nothing!
+func (session *telnetSession) Scale() float64 {
+	return 1
+}
+
+// SetScale does nothing!
+func (session *telnetSession) SetScale(scale float64) {
+}
+
+// SetTitle sets the client's terminal title.
+func (session *telnetSession) SetTitle(title string) {
+	session.write([]byte("\x1b]0;" + stripControl(title) + "\x07"))
+}
+
+// SetGlyphs does nothing!
+func (session *telnetSession) SetGlyphs(id glyphs.ID, path string, size float64) error {
+	return nil
+}
+
+// SyncSize does nothing!
+func (session *telnetSession) SyncSize() {
+	return
+}
+
+// Screens returns the collection of Screens drawn to the connection.
+func (session *telnetSession) Screens() *Screens {
+	return &session.screens
+}
@@ -0,0 +1,95 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// startTelnet starts a telnet backend on a loopback port and returns it along with a client connection and the connection's Screen.
+func startTelnet(t *testing.T) (*BackendTelnet, net.Conn, *Screen) {
+	backend := &BackendTelnet{Address: "127.0.0.1:0"}
+	if err := backend.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screenChan := make(chan *Screen, 1)
+	go backend.Run(context.Background(), func(screen *Screen) {
+		screenChan <- screen
+	})
+	conn, err := net.Dial("tcp", backend.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go io.Copy(ioutil.Discard, conn)
+	select {
+	case screen := <-screenChan:
+		return backend, conn, screen
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the connection's Screen")
+	}
+	return nil, nil, nil
+}
+
+// sendTelnet writes each part to conn as a separate write, pausing between them so they arrive in separate reads.
+func sendTelnet(t *testing.T, conn net.Conn, parts ...string) {
+	for _, part := range parts {
+		if _, err := conn.Write([]byte(part)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// expectEvent waits for the Screen's next event and fails if it is not want.
+func expectEvent(t *testing.T, screen *Screen, want Event) {
+	t.Helper()
+	event := screen.WaitEventTimeout(5 * time.Second)
+	if event != want {
+		t.Fatalf("got event %#v, want %#v", event, want)
+	}
+}
+
+func TestTelnetInput(t *testing.T) {
+	backend, conn, screen := startTelnet(t)
+	defer backend.Quit()
+	defer conn.Close()
+
+	// A window size of 255 columns doubles the 255 byte, and the command is split across reads.
+	sendTelnet(t, conn, "\xff\xfa\x1f\x00\xff", "\xff\x00\x1e\xff\xf0")
+	expectEvent(t, screen, EventResize{Columns: 255, Rows: 30})
+	if columns, rows := screen.backend.Size(); columns != 255 || rows != 30 {
+		t.Fatalf("got size %dx%d, want 255x30", columns, rows)
+	}
+
+	// Negotiation split after IAC must not be read as keys.
+	sendTelnet(t, conn, "\xff", "\xfb\x01x")
+	expectEvent(t, screen, EventKey{Rune: 'x', Key: RuneToKeyMap['x']})
+
+	// An arrow key split in the middle of its escape sequence.
+	sendTelnet(t, conn, "\x1b[1;", "5A")
+	expectEvent(t, screen, EventKey{Key: KeyUp, Ctrl: true})
+
+	sendTelnet(t, conn, "\x1bO", "P")
+	expectEvent(t, screen, EventKey{Key: KeyF1})
+}
@@ -19,6 +19,8 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 */
 
 import (
+	"context"
+
 	"github.com/kettek/goro/glyphs"
 )
 
@@ -53,8 +55,8 @@ func (backend *BackendVirtual) Setup(cb func(*Screen)) (err error) {
 	return err
 }
 
-// Run runs the given function cb as a goroutine.
-func (backend *BackendVirtual) Run(cb func(*Screen)) (err error) {
+// Run does nothing!
+func (backend *BackendVirtual) Run(ctx context.Context, cb func(*Screen)) (err error) {
 	return err
 }
 
@@ -95,3 +97,8 @@ func (backend *BackendVirtual) SetGlyphs(id glyphs.ID, path string, size float64
 func (backend *BackendVirtual) SyncSize() {
 	return
 }
+
+// Screens returns nil, as virtual screens are not displayed.
+func (backend *BackendVirtual) Screens() *Screens {
+	return nil
+}
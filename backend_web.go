@@ -0,0 +1,542 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/kettek/goro/glyphs"
+)
+
+/*
+Messages sent to the browser are binary WebSocket messages starting with a message type byte. Multi-byte values are little-endian.
+
+	webMessageTitle     title as UTF-8
+	webMessageSize      uint16 columns, uint16 rows
+	webMessageDefaults  foreground R, G, B, A, background R, G, B, A
+	webMessageCells     repeated cells of: uint16 x, uint16 y, uint32 rune,
+	                    foreground R, G, B, A, background R, G, B, A, uint8 style flags, uint8 glyphs ID
+	webMessageClear     no payload; all cells are reset to the default colors
+	webMessageScroll    uint16 x, y, width, height, int16 dx, dy; the cells of the region are
+	                    moved by dx and dy, and the cells left behind are sent afterwards
+
+Messages received from the browser are JSON text messages described by webInput.
+*/
+const (
+	webMessageTitle = iota + 1
+	webMessageSize
+	webMessageDefaults
+	webMessageCells
+	webMessageClear
+	webMessageScroll
+)
+
+// webCellSize is the encoded size of a single cell within a webMessageCells message.
+const webCellSize = 18
+
+// BackendWeb is a backend that serves a web page which renders a Screen on a canvas, with each browser connection receiving its own Screen over a WebSocket. WebSocket connections are only accepted from pages served by the same host, or from the origins, such as "https://example.com", listed in AllowedOrigins.
+type BackendWeb struct {
+	Address        string
+	AllowedOrigins []string
+	listener       net.Listener
+	server         *http.Server
+	setupCb        func(*Screen)
+	runCb          func(*Screen)
+	ctx            context.Context
+	sessions       map[*webSession]struct{}
+	title          string
+	quitting       bool
+	mutex          sync.Mutex
+}
+
+// InitWeb initializes the web backend to serve on the provided address, such as ":8080". Calls BackendWeb.Init().
+func InitWeb(address string) error {
+	return Init(Backend(&BackendWeb{Address: address}))
+}
+
+// Init starts listening on the backend's Address.
+func (backend *BackendWeb) Init() (err error) {
+	backend.sessions = make(map[*webSession]struct{})
+	backend.title = "goro - Web"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", backend.serveClient)
+	mux.HandleFunc("/ws", backend.serveWebSocket)
+	backend.server = &http.Server{Handler: mux}
+
+	backend.listener, err = net.Listen("tcp", backend.Address)
+	return err
+}
+
+// Addr returns the address the backend is listening on.
+func (backend *BackendWeb) Addr() net.Addr {
+	return backend.listener.Addr()
+}
+
+// Quit stops the server and closes all connections.
+func (backend *BackendWeb) Quit() {
+	backend.mutex.Lock()
+	backend.quitting = true
+	sessions := make([]*webSession, 0, len(backend.sessions))
+	for session := range backend.sessions {
+		sessions = append(sessions, session)
+	}
+	backend.mutex.Unlock()
+
+	backend.server.Close()
+	for _, session := range sessions {
+		session.Quit()
+	}
+}
+
+// Setup stores the given function cb to be called with each new connection's Screen before it is passed to Run's callback.
+func (backend *BackendWeb) Setup(cb func(*Screen)) (err error) {
+	backend.setupCb = cb
+	return nil
+}
+
+// Run serves the web page and WebSocket connections until ctx is done, running the given function cb as a goroutine for each connection's Screen.
+func (backend *BackendWeb) Run(ctx context.Context, cb func(*Screen)) (err error) {
+	backend.mutex.Lock()
+	backend.ctx = ctx
+	backend.runCb = cb
+	backend.mutex.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			backend.Quit()
+		case <-done:
+		}
+	}()
+
+	err = backend.server.Serve(backend.listener)
+
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	if backend.quitting {
+		return nil
+	}
+	return err
+}
+
+// serveClient serves the page containing the canvas renderer.
+func (backend *BackendWeb) serveClient(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(webClientHTML))
+}
+
+// serveWebSocket runs a single browser connection's session until it disconnects. The session's Screens use a context derived from Run's that is canceled on disconnect.
+func (backend *BackendWeb) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := wsUpgrade(w, r, backend.AllowedOrigins)
+	if err != nil {
+		return
+	}
+	session := newWebSession(backend, ws)
+
+	backend.mutex.Lock()
+	backend.sessions[session] = struct{}{}
+	title := backend.title
+	ctx, cancel := context.WithCancel(backend.ctx)
+	runCb := backend.runCb
+	backend.mutex.Unlock()
+	defer cancel()
+
+	if err := session.screen.Init(session); err != nil {
+		ws.Close()
+		return
+	}
+	session.screens.backend = session
+	session.screens.Add(&session.screen)
+	session.screens.setContext(ctx)
+	session.SetTitle(title)
+	if backend.setupCb != nil {
+		backend.setupCb(&session.screen)
+	}
+
+	go session.drawLoop()
+	go session.screens.runTicks(ctx)
+	go runCb(&session.screen)
+
+	session.readLoop()
+
+	backend.mutex.Lock()
+	delete(backend.sessions, session)
+	backend.mutex.Unlock()
+}
+
+// Refresh does nothing, as each connection is refreshed by its own Screen.
+func (backend *BackendWeb) Refresh() {
+}
+
+// Size returns the default size of new connections' Screens.
+func (backend *BackendWeb) Size() (int, int) {
+	return 80, 24
+}
+
+// SetSize does nothing!
+func (backend *BackendWeb) SetSize(w, h int) {
+}
+
+// Units returns the unit type the backend uses for Size().
+func (backend *BackendWeb) Units() int {
+	return UnitCells
+}
+
+// Scale returns the current backend window scaling. Does nothing.
+func (backend *BackendWeb) Scale() float64 {
+	return 1
+}
+
+// SetScale sets the backend window's scaling. Does nothing.
+func (backend *BackendWeb) SetScale(scale float64) {
+}
+
+// SetTitle sets the page title of all current and future connections.
+func (backend *BackendWeb) SetTitle(title string) {
+	backend.mutex.Lock()
+	backend.title = title
+	sessions := make([]*webSession, 0, len(backend.sessions))
+	for session := range backend.sessions {
+		sessions = append(sessions, session)
+	}
+	backend.mutex.Unlock()
+
+	for _, session := range sessions {
+		session.SetTitle(title)
+	}
+}
+
+// SetGlyphs does nothing!
+func (backend *BackendWeb) SetGlyphs(id glyphs.ID, path string, size float64) error {
+	return nil
+}
+
+// SyncSize does nothing!
+func (backend *BackendWeb) SyncSize() {
+	return
+}
+
+// Screens returns nil, as each connection has its own Screens.
+func (backend *BackendWeb) Screens() *Screens {
+	return nil
+}
+
+// webInput is an input message sent by the browser.
+type webInput struct {
+	Type    string `json:"type"`
+	Key     string `json:"key"`
+	Shift   bool   `json:"shift"`
+	Ctrl    bool   `json:"ctrl"`
+	Alt     bool   `json:"alt"`
+	Meta    bool   `json:"meta"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Button  int    `json:"button"`
+	State   bool   `json:"state"`
+	Columns int    `json:"columns"`
+	Rows    int    `json:"rows"`
+}
+
+// webKeyMap maps the browser's KeyboardEvent.key names to keys.
+var webKeyMap = map[string]Key{
+	"ArrowUp":    KeyUp,
+	"ArrowDown":  KeyDown,
+	"ArrowLeft":  KeyLeft,
+	"ArrowRight": KeyRight,
+	"Enter":      KeyEnter,
+	"Escape":     KeyEscape,
+	"Backspace":  KeyBackspace,
+	"Tab":        KeyTab,
+	"Delete":     KeyDelete,
+	"Insert":     KeyInsert,
+	"Home":       KeyHome,
+	"End":        KeyEnd,
+	"PageUp":     KeyPageUp,
+	"PageDown":   KeyPageDown,
+	"F1":         KeyF1,
+	"F2":         KeyF2,
+	"F3":         KeyF3,
+	"F4":         KeyF4,
+	"F5":         KeyF5,
+	"F6":         KeyF6,
+	"F7":         KeyF7,
+	"F8":         KeyF8,
+	"F9":         KeyF9,
+	"F10":        KeyF10,
+	"F11":        KeyF11,
+	"F12":        KeyF12,
+}
+
+// webSession is a single browser connection. It acts as the Backend of its Screen.
+type webSession struct {
+	backend       *BackendWeb
+	ws            *wsConn
+	screen        Screen
+	screens       Screens
+	columns, rows int
+	sizeMutex     sync.Mutex
+	sentSize      [2]int
+	sentDefaults  [2]Color
+	refreshChan   chan struct{}
+	closeChan     chan struct{}
+	closeOnce     sync.Once
+}
+
+func newWebSession(backend *BackendWeb, ws *wsConn) *webSession {
+	return &webSession{
+		backend:     backend,
+		ws:          ws,
+		columns:     80,
+		rows:        24,
+		sentSize:    [2]int{-1, -1},
+		refreshChan: make(chan struct{}, 1),
+		closeChan:   make(chan struct{}),
+	}
+}
+
+// readLoop reads and handles input messages until the connection closes, then closes the Screen.
+func (session *webSession) readLoop() {
+	for {
+		opcode, message, err := session.ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		if opcode != wsOpText {
+			continue
+		}
+		var input webInput
+		if err := json.Unmarshal(message, &input); err != nil {
+			continue
+		}
+		session.handleInput(input)
+	}
+	session.Quit()
+}
+
+// handleInput converts a browser input message into events.
+func (session *webSession) handleInput(input webInput) {
+	switch input.Type {
+	case "key":
+		var event EventKey
+		if key, ok := webKeyMap[input.Key]; ok {
+			event.Key = key
+		} else if utf8.RuneCountInString(input.Key) == 1 {
+			r, _ := utf8.DecodeRuneInString(input.Key)
+			event = runeToEventKey(r)
+		} else {
+			return
+		}
+		event.Shift = event.Shift || input.Shift
+		event.Ctrl = input.Ctrl
+		event.Alt = input.Alt
+		event.Meta = input.Meta
+		session.screens.sendKey(event)
+	case "mouse":
+		session.screens.sendMouse(EventMouse{X: input.X, Y: input.Y, Button: input.Button, State: input.State})
+	case "resize":
+		if input.Columns <= 0 || input.Rows <= 0 {
+			return
+		}
+		session.sizeMutex.Lock()
+		session.columns, session.rows = input.Columns, input.Rows
+		session.sizeMutex.Unlock()
+		session.screens.sendResize(input.Columns, input.Rows)
+		session.Refresh()
+	}
+}
+
+// drawLoop draws the Screen whenever it is refreshed.
+func (session *webSession) drawLoop() {
+	for {
+		select {
+		case <-session.refreshChan:
+			session.draw()
+		case <-session.closeChan:
+			return
+		}
+	}
+}
+
+// draw sends the Screen's size and default colors if they have changed, followed by the Screens' scrolls and the cells that need redrawing.
+func (session *webSession) draw() {
+	var messages [][]byte
+	cells := []byte{webMessageCells}
+
+	session.screen.cellsMutex.Lock()
+	fg, bg := session.screen.Foreground, session.screen.Background
+	rows := len(session.screen.cells)
+	columns := 0
+	if rows > 0 {
+		columns = len(session.screen.cells[0])
+	}
+	if session.sentSize != [2]int{columns, rows} {
+		session.sentSize = [2]int{columns, rows}
+		message := []byte{webMessageSize, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint16(message[1:], uint16(columns))
+		binary.LittleEndian.PutUint16(message[3:], uint16(rows))
+		messages = append(messages, message)
+	}
+	session.screen.cellsMutex.Unlock()
+	if session.sentDefaults != [2]Color{fg, bg} {
+		session.sentDefaults = [2]Color{fg, bg}
+		messages = append(messages, []byte{webMessageDefaults, fg.R, fg.G, fg.B, fg.A, bg.R, bg.G, bg.B, bg.A})
+	}
+
+	session.screens.scroll(func(rect Rect, dx, dy int) bool {
+		if rect.X < 0 || rect.Y < 0 || rect.X+rect.Width > columns || rect.Y+rect.Height > rows {
+			return false
+		}
+		if dx >= rect.Width || -dx >= rect.Width || dy >= rect.Height || -dy >= rect.Height {
+			return false
+		}
+		message := make([]byte, 13)
+		message[0] = webMessageScroll
+		binary.LittleEndian.PutUint16(message[1:], uint16(rect.X))
+		binary.LittleEndian.PutUint16(message[3:], uint16(rect.Y))
+		binary.LittleEndian.PutUint16(message[5:], uint16(rect.Width))
+		binary.LittleEndian.PutUint16(message[7:], uint16(rect.Height))
+		binary.LittleEndian.PutUint16(message[9:], uint16(int16(dx)))
+		binary.LittleEndian.PutUint16(message[11:], uint16(int16(dy)))
+		messages = append(messages, message)
+		return true
+	})
+
+	var buf [webCellSize]byte
+	session.screens.redraw(func() {
+		messages = append(messages, []byte{webMessageClear})
+	}, func(x, y int, screen *Screen, cell *Cell) {
+		fg, bg := cell.Style.Foreground, cell.Style.Background
+		// Other Screens may not share the browser's default colors.
+		if screen != &session.screen {
+			if fg == ColorNone {
+				fg = screen.Foreground
+			}
+			if bg == ColorNone {
+				bg = screen.Background
+			}
+		}
+		binary.LittleEndian.PutUint16(buf[0:], uint16(x))
+		binary.LittleEndian.PutUint16(buf[2:], uint16(y))
+		binary.LittleEndian.PutUint32(buf[4:], uint32(cell.Rune))
+		copy(buf[8:], []byte{fg.R, fg.G, fg.B, fg.A, bg.R, bg.G, bg.B, bg.A})
+		buf[16] = styleToFlags(cell.Style)
+		buf[17] = byte(cell.Glyphs)
+		cells = append(cells, buf[:]...)
+	})
+
+	if len(cells) > 1 {
+		messages = append(messages, cells)
+	}
+	for _, message := range messages {
+		if err := session.ws.WriteMessage(wsOpBinary, message); err != nil {
+			return
+		}
+	}
+}
+
+// Init does nothing, as sessions are initialized by BackendWeb.
+func (session *webSession) Init() error {
+	return nil
+}
+
+// Setup does nothing, as sessions are set up by BackendWeb.
+func (session *webSession) Setup(cb func(*Screen)) error {
+	return nil
+}
+
+// Run does nothing, as sessions are run by BackendWeb.
+func (session *webSession) Run(ctx context.Context, cb func(*Screen)) error {
+	return nil
+}
+
+// Refresh causes the session to redraw its Screen.
+func (session *webSession) Refresh() {
+	select {
+	case session.refreshChan <- struct{}{}:
+	default:
+	}
+}
+
+// Quit closes the connection.
+func (session *webSession) Quit() {
+	session.closeOnce.Do(func() {
+		session.screen.Close()
+		session.ws.WriteMessage(wsOpClose, nil)
+		close(session.closeChan)
+		session.ws.Close()
+	})
+}
+
+// Size returns the size of the browser's canvas in cells.
+func (session *webSession) Size() (int, int) {
+	session.sizeMutex.Lock()
+	defer session.sizeMutex.Unlock()
+	return session.columns, session.rows
+}
+
+// SetSize does nothing!
+func (session *webSession) SetSize(w, h int) {
+}
+
+// Units returns the unit type the session uses for Size().
+func (session *webSession) Units() int {
+	return UnitCells
+}
+
+// Scale does nothing!
+func (session *webSession) Scale() float64 {
+	return 1
+}
+
+// SetScale does nothing!
+func (session *webSession) SetScale(scale float64) {
+}
+
+// SetTitle sets the browser page's title.
+func (session *webSession) SetTitle(title string) {
+	session.ws.WriteMessage(wsOpBinary, append([]byte{webMessageTitle}, title...))
+}
+
+// SetGlyphs does nothing!
+func (session *webSession) SetGlyphs(id glyphs.ID, path string, size float64) error {
+	return nil
+}
+
+// SyncSize refreshes the session so that the browser receives the Screen's new size.
+func (session *webSession) SyncSize() {
+	session.Refresh()
+}
+
+// Screens returns the collection of Screens drawn to the browser.
+func (session *webSession) Screens() *Screens {
+	return &session.screens
+}
@@ -0,0 +1,216 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+// webClientHTML is the page served by BackendWeb. It connects back to the backend's WebSocket, renders received cells onto a canvas, and sends key, mouse, and resize input.
+const webClientHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>goro</title>
+<style>
+html, body { margin: 0; height: 100%; background: #000; overflow: hidden; }
+canvas { display: block; }
+</style>
+</head>
+<body>
+<canvas id="screen"></canvas>
+<script>
+(function() {
+  var canvas = document.getElementById('screen');
+  var ctx = canvas.getContext('2d');
+  var fontSize = 16;
+  var fontFamily = 'monospace';
+  ctx.font = fontSize + 'px ' + fontFamily;
+  var cellWidth = Math.ceil(ctx.measureText('M').width);
+  var cellHeight = Math.ceil(fontSize * 1.25);
+  var columns = 0, rows = 0;
+  var defaultFg = 'rgb(192,192,192)', defaultBg = 'rgb(0,0,0)';
+  var cells = [];
+  var decoder = new TextDecoder();
+
+  var ws = new WebSocket((location.protocol === 'https:' ? 'wss://' : 'ws://') + location.host + '/ws');
+  ws.binaryType = 'arraybuffer';
+
+  function send(message) {
+    if (ws.readyState === WebSocket.OPEN) {
+      ws.send(JSON.stringify(message));
+    }
+  }
+
+  function sendSize() {
+    send({
+      type: 'resize',
+      columns: Math.max(1, Math.floor(window.innerWidth / cellWidth)),
+      rows: Math.max(1, Math.floor(window.innerHeight / cellHeight))
+    });
+  }
+
+  function color(c, fallback) {
+    if (c[3] === 0) {
+      return fallback;
+    }
+    return 'rgba(' + c[0] + ',' + c[1] + ',' + c[2] + ',' + (c[3] / 255) + ')';
+  }
+
+  function drawCell(x, y) {
+    var cell = cells[y * columns + x];
+    var fg = defaultFg, bg = defaultBg, r = 0, flags = 0;
+    if (cell) {
+      fg = color(cell.fg, defaultFg);
+      bg = color(cell.bg, defaultBg);
+      r = cell.r;
+      flags = cell.flags;
+    }
+    if (flags & 16) {
+      var t = fg; fg = bg; bg = t;
+    }
+    var px = x * cellWidth, py = y * cellHeight;
+    ctx.fillStyle = defaultBg;
+    ctx.fillRect(px, py, cellWidth, cellHeight);
+    ctx.fillStyle = bg;
+    ctx.fillRect(px, py, cellWidth, cellHeight);
+    if (r === 0 || r === 32) {
+      return;
+    }
+    ctx.globalAlpha = (flags & 8) ? 0.5 : 1;
+    ctx.font = ((flags & 4) ? 'bold ' : '') + fontSize + 'px ' + fontFamily;
+    ctx.fillStyle = fg;
+    ctx.textBaseline = 'middle';
+    ctx.textAlign = 'center';
+    ctx.fillText(String.fromCodePoint(r), px + cellWidth / 2, py + cellHeight / 2);
+    if (flags & 2) {
+      ctx.fillRect(px, py + cellHeight - 2, cellWidth, 1);
+    }
+    ctx.globalAlpha = 1;
+  }
+
+  function drawAll() {
+    for (var y = 0; y < rows; y++) {
+      for (var x = 0; x < columns; x++) {
+        drawCell(x, y);
+      }
+    }
+  }
+
+  function resize(newColumns, newRows) {
+    var newCells = [];
+    for (var y = 0; y < Math.min(rows, newRows); y++) {
+      for (var x = 0; x < Math.min(columns, newColumns); x++) {
+        newCells[y * newColumns + x] = cells[y * columns + x];
+      }
+    }
+    cells = newCells;
+    columns = newColumns;
+    rows = newRows;
+    canvas.width = columns * cellWidth;
+    canvas.height = rows * cellHeight;
+    drawAll();
+  }
+
+  // scroll moves the cells of a region by dx and dy. The cells left behind are sent by the server afterwards.
+  function scroll(rx, ry, width, height, dx, dy) {
+    var w = width - Math.abs(dx), h = height - Math.abs(dy);
+    var sx = rx + Math.max(0, -dx), sy = ry + Math.max(0, -dy);
+    var moved = [];
+    for (var y = 0; y < h; y++) {
+      for (var x = 0; x < w; x++) {
+        moved.push(cells[(sy + y) * columns + sx + x]);
+      }
+    }
+    for (var y = 0; y < h; y++) {
+      for (var x = 0; x < w; x++) {
+        cells[(sy + dy + y) * columns + sx + dx + x] = moved[y * w + x];
+      }
+    }
+    ctx.drawImage(canvas, sx * cellWidth, sy * cellHeight, w * cellWidth, h * cellHeight, (sx + dx) * cellWidth, (sy + dy) * cellHeight, w * cellWidth, h * cellHeight);
+  }
+
+  ws.onopen = sendSize;
+  ws.onclose = function() {
+    document.title += ' (disconnected)';
+  };
+  ws.onmessage = function(e) {
+    var view = new DataView(e.data);
+    switch (view.getUint8(0)) {
+    case 1:
+      document.title = decoder.decode(new Uint8Array(e.data, 1));
+      break;
+    case 2:
+      resize(view.getUint16(1, true), view.getUint16(3, true));
+      break;
+    case 3:
+      defaultFg = color([view.getUint8(1), view.getUint8(2), view.getUint8(3), view.getUint8(4)], 'rgb(192,192,192)');
+      defaultBg = color([view.getUint8(5), view.getUint8(6), view.getUint8(7), view.getUint8(8)], 'rgb(0,0,0)');
+      drawAll();
+      break;
+    case 4:
+      for (var o = 1; o + 18 <= view.byteLength; o += 18) {
+        var x = view.getUint16(o, true), y = view.getUint16(o + 2, true);
+        if (x >= columns || y >= rows) {
+          continue;
+        }
+        cells[y * columns + x] = {
+          r: view.getUint32(o + 4, true),
+          fg: [view.getUint8(o + 8), view.getUint8(o + 9), view.getUint8(o + 10), view.getUint8(o + 11)],
+          bg: [view.getUint8(o + 12), view.getUint8(o + 13), view.getUint8(o + 14), view.getUint8(o + 15)],
+          flags: view.getUint8(o + 16)
+        };
+        drawCell(x, y);
+      }
+      break;
+    case 5:
+      cells = [];
+      drawAll();
+      break;
+    case 6:
+      scroll(view.getUint16(1, true), view.getUint16(3, true), view.getUint16(5, true), view.getUint16(7, true), view.getInt16(9, true), view.getInt16(11, true));
+      break;
+    }
+  };
+
+  window.addEventListener('resize', sendSize);
+  window.addEventListener('keydown', function(e) {
+    send({type: 'key', key: e.key, shift: e.shiftKey, ctrl: e.ctrlKey, alt: e.altKey, meta: e.metaKey});
+    if (!e.metaKey) {
+      e.preventDefault();
+    }
+  });
+  function mouse(state) {
+    return function(e) {
+      var rect = canvas.getBoundingClientRect();
+      send({
+        type: 'mouse',
+        x: Math.floor((e.clientX - rect.left) / cellWidth),
+        y: Math.floor((e.clientY - rect.top) / cellHeight),
+        button: e.button,
+        state: state
+      });
+    };
+  }
+  canvas.addEventListener('mousedown', mouse(true));
+  canvas.addEventListener('mouseup', mouse(false));
+  canvas.addEventListener('contextmenu', function(e) {
+    e.preventDefault();
+  });
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,263 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startWeb starts a web backend on a loopback port that allows the given origins and returns it along with a channel that receives each connection's Screen.
+func startWeb(t *testing.T, allowedOrigins ...string) (*BackendWeb, chan *Screen) {
+	backend := &BackendWeb{Address: "127.0.0.1:0", AllowedOrigins: allowedOrigins}
+	if err := backend.Init(); err != nil {
+		t.Fatal(err)
+	}
+	backend.Setup(func(screen *Screen) {
+		screen.UseMouse = true
+	})
+	screenChan := make(chan *Screen, 1)
+	go backend.Run(context.Background(), func(screen *Screen) {
+		screenChan <- screen
+	})
+	return backend, screenChan
+}
+
+// webClient is the client side of a WebSocket connection, which masks the frames it sends.
+type webClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// ReadMessage reads a single unmasked frame sent by the server.
+func (client *webClient) ReadMessage() (opcode byte, message []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(client.reader, header[:]); err != nil {
+		return
+	}
+	length := int(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(client.reader, ext[:]); err != nil {
+			return
+		}
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(client.reader, ext[:]); err != nil {
+			return
+		}
+		length = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	message = make([]byte, length)
+	_, err = io.ReadFull(client.reader, message)
+	return header[0] & 0x0F, message, err
+}
+
+// WriteMessage writes payload as a single frame, masked unless masked is false.
+func (client *webClient) WriteMessage(opcode byte, payload []byte, masked bool) error {
+	frame := []byte{0x80 | opcode, byte(len(payload))}
+	if masked {
+		mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+		frame[1] |= 0x80
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+	_, err := client.conn.Write(frame)
+	return err
+}
+
+// Close closes the connection.
+func (client *webClient) Close() error {
+	return client.conn.Close()
+}
+
+// dialWeb performs the WebSocket handshake with the backend using the given Origin, returning the connection and the handshake's status code.
+func dialWeb(t *testing.T, backend *BackendWeb, origin string) (*webClient, int) {
+	conn, err := net.Dial("tcp", backend.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := "GET /ws HTTP/1.1\r\n" +
+		"Host: " + backend.Addr().String() + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Origin: " + origin + "\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, response.StatusCode
+	}
+	if response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("got accept key %q", response.Header.Get("Sec-WebSocket-Accept"))
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &webClient{conn: conn, reader: reader}, response.StatusCode
+}
+
+func TestWebOrigin(t *testing.T) {
+	backend, _ := startWeb(t)
+	defer backend.Quit()
+	if _, status := dialWeb(t, backend, "http://example.com"); status != http.StatusForbidden {
+		t.Fatalf("got status %d for a foreign origin, want %d", status, http.StatusForbidden)
+	}
+
+	allowing, _ := startWeb(t, "http://example.com")
+	defer allowing.Quit()
+	ws, status := dialWeb(t, allowing, "http://example.com")
+	if status != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d for an allowed origin", status)
+	}
+	ws.Close()
+}
+
+func TestWebSession(t *testing.T) {
+	backend, screenChan := startWeb(t)
+	defer backend.Quit()
+
+	ws, status := dialWeb(t, backend, "http://"+backend.Addr().String())
+	if status != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d for the same origin", status)
+	}
+	defer ws.Close()
+	var screen *Screen
+	select {
+	case screen = <-screenChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the connection's Screen")
+	}
+
+	screen.DrawRune(1, 2, '@', Style{Foreground: ColorRed})
+	screen.Flush()
+
+	var sawSize, sawCell bool
+	for !sawCell {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch message[0] {
+		case webMessageSize:
+			columns, rows := binary.LittleEndian.Uint16(message[1:]), binary.LittleEndian.Uint16(message[3:])
+			if columns != 80 || rows != 24 {
+				t.Fatalf("got size %dx%d, want 80x24", columns, rows)
+			}
+			sawSize = true
+		case webMessageCells:
+			for cell := message[1:]; len(cell) >= webCellSize; cell = cell[webCellSize:] {
+				x, y := binary.LittleEndian.Uint16(cell), binary.LittleEndian.Uint16(cell[2:])
+				if x == 1 && y == 2 {
+					if r := rune(binary.LittleEndian.Uint32(cell[4:])); r != '@' {
+						t.Fatalf("got rune %q at 1,2, want '@'", r)
+					}
+					if fg := (Color{R: cell[8], G: cell[9], B: cell[10], A: cell[11]}); fg != ColorRed {
+						t.Fatalf("got foreground %v at 1,2, want %v", fg, ColorRed)
+					}
+					sawCell = true
+				}
+			}
+		}
+	}
+	if !sawSize {
+		t.Fatal("cells were sent before the size")
+	}
+
+	// Scrolling sends the move rather than the moved cells.
+	screen.Scroll(Rect{0, 0, 80, 24}, 0, -1, ' ', Style{})
+	screen.Flush()
+	_, message, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message[0] != webMessageScroll || binary.LittleEndian.Uint16(message[5:]) != 80 || int16(binary.LittleEndian.Uint16(message[11:])) != -1 {
+		t.Fatalf("got message %v, want a scroll", message)
+	}
+	if _, message, err = ws.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if message[0] != webMessageCells || len(message) != 1+80*webCellSize {
+		t.Fatalf("got message of type %d and length %d, want only the bottom row's cells", message[0], len(message))
+	}
+
+	inputs := []struct {
+		message string
+		want    Event
+	}{
+		{`{"type":"key","key":"ArrowUp","shift":true}`, EventKey{Key: KeyUp, Shift: true}},
+		{`{"type":"key","key":"x","ctrl":true}`, EventKey{Key: RuneToKeyMap['x'], Rune: 'x', Ctrl: true}},
+		{`{"type":"mouse","x":3,"y":4,"button":1,"state":true}`, EventMouse{X: 3, Y: 4, Button: 1, State: true}},
+		{`{"type":"resize","columns":100,"rows":30}`, EventResize{Columns: 100, Rows: 30}},
+	}
+	for _, input := range inputs {
+		if err := ws.WriteMessage(wsOpText, []byte(input.message), true); err != nil {
+			t.Fatal(err)
+		}
+		expectEvent(t, screen, input.want)
+	}
+	if columns, rows := screen.backend.Size(); columns != 100 || rows != 30 {
+		t.Fatalf("got size %dx%d, want 100x30", columns, rows)
+	}
+}
+
+func TestWebUnmasked(t *testing.T) {
+	backend, _ := startWeb(t)
+	defer backend.Quit()
+
+	ws, status := dialWeb(t, backend, "http://"+backend.Addr().String())
+	if status != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d for the same origin", status)
+	}
+	defer ws.Close()
+	if err := ws.WriteMessage(wsOpText, []byte(`{"type":"key","key":"x"}`), false); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		opcode, message, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("connection ended without a close frame: %v", err)
+		}
+		if opcode != wsOpClose {
+			continue
+		}
+		if len(message) < 2 || int(binary.BigEndian.Uint16(message)) != wsCloseProtocolError {
+			t.Fatalf("got close payload %v, want status %d", message, wsCloseProtocolError)
+		}
+		return
+	}
+}
@@ -0,0 +1,100 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"github.com/kettek/goro/glyphs"
+)
+
+// Batch draws to a Screen's cells while the Screen's lock is held, so that drawing many cells does not lock and unlock for each one. A Batch is only valid within the function passed to Screen.Batch.
+type Batch struct {
+	screen *Screen
+}
+
+// Batch calls fn with a Batch for drawing to the Screen, holding the Screen's lock until fn returns. The Screen's own methods must not be called from within fn.
+func (screen *Screen) Batch(fn func(batch *Batch)) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	fn(&Batch{screen: screen})
+}
+
+// Size returns the Screen's columns and rows.
+func (batch *Batch) Size() (int, int) {
+	return batch.screen.Columns, batch.screen.Rows
+}
+
+// Cell returns a copy of the Cell at the given location.
+func (batch *Batch) Cell(x int, y int) (Cell, error) {
+	return batch.screen.getCell(x, y)
+}
+
+// DrawRune draws a given rune at the position of x and y with a given style.
+func (batch *Batch) DrawRune(x int, y int, r rune, s Style) error {
+	return batch.screen.drawRune(x, y, r, s)
+}
+
+// DrawString draws a string at the position of x and y with a given style, iterating in the x direction as it goes.
+func (batch *Batch) DrawString(x int, y int, str string, s Style) error {
+	return batch.screen.drawString(x, y, str, s)
+}
+
+// DrawRole draws a given rune at the position of x and y with the style of role in the Screen's theme.
+func (batch *Batch) DrawRole(x int, y int, r rune, role string) error {
+	return batch.screen.drawRole(x, y, r, role)
+}
+
+// DrawStringRole draws a string at the position of x and y with the style of role.
+func (batch *Batch) DrawStringRole(x int, y int, str string, role string) error {
+	return batch.screen.drawStringRole(x, y, str, role)
+}
+
+// SetForeground sets the foreground at the given location.
+func (batch *Batch) SetForeground(x int, y int, c Color) error {
+	return batch.screen.setForeground(x, y, c)
+}
+
+// SetBackground sets the background at the given location.
+func (batch *Batch) SetBackground(x int, y int, c Color) error {
+	return batch.screen.setBackground(x, y, c)
+}
+
+// SetStyle sets the style at the given location.
+func (batch *Batch) SetStyle(x int, y int, s Style) error {
+	return batch.screen.setStyle(x, y, s)
+}
+
+// SetRole sets the style at the given location to that of role.
+func (batch *Batch) SetRole(x int, y int, role string) error {
+	return batch.screen.setRole(x, y, role)
+}
+
+// SetRune sets the rune at the given location.
+func (batch *Batch) SetRune(x int, y int, r rune) error {
+	return batch.screen.setRune(x, y, r)
+}
+
+// SetGlyphsID sets the glyphs at a given location.
+func (batch *Batch) SetGlyphsID(x int, y int, id glyphs.ID) error {
+	return batch.screen.setGlyphsID(x, y, id)
+}
+
+// SetOffset sets how far the rune at the given location is shifted from the center of its cell, as a fraction of a cell.
+func (batch *Batch) SetOffset(x int, y int, offsetX, offsetY float64) error {
+	return batch.screen.setOffset(x, y, offsetX, offsetY)
+}
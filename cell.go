@@ -32,4 +32,9 @@ type Cell struct {
 	PendingStyle  Style
 	PendingGlyphs glyphs.ID
 	Glyphs        glyphs.ID
+	// Offsets shift the cell's rune by a fraction of a cell, for backends that can draw between cells.
+	PendingOffsetX, PendingOffsetY float64
+	OffsetX, OffsetY               float64
+	// Roles are the theme roles the cell's styles were drawn with, if any, so that they can be restyled when the theme changes.
+	PendingRole, Role string
 }
@@ -0,0 +1,275 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"math"
+	"sort"
+)
+
+// clampChannel rounds v to the nearest color channel value, clamped to 0-255.
+func clampChannel(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	} else if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// ColorToHSV returns the hue in degrees [0, 360), and the saturation and value in [0, 1], of c.
+func ColorToHSV(c Color) (h, s, v float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	h = hue(r, g, b, max, min)
+	if max > 0 {
+		s = (max - min) / max
+	}
+	return h, s, max
+}
+
+// ColorFromHSV returns the opaque color with the hue in degrees, and the saturation and value in [0, 1].
+func ColorFromHSV(h, s, v float64) Color {
+	c := v * s
+	return colorFromHueChroma(h, c, v-c)
+}
+
+// ColorToHSL returns the hue in degrees [0, 360), and the saturation and lightness in [0, 1], of c.
+func ColorToHSL(c Color) (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	h = hue(r, g, b, max, min)
+	l = (max + min) / 2
+	if max != min {
+		s = (max - min) / (1 - math.Abs(2*l-1))
+	}
+	return h, s, l
+}
+
+// ColorFromHSL returns the opaque color with the hue in degrees, and the saturation and lightness in [0, 1].
+func ColorFromHSL(h, s, l float64) Color {
+	c := (1 - math.Abs(2*l-1)) * s
+	return colorFromHueChroma(h, c, l-c/2)
+}
+
+// hue returns the hue in degrees of the RGB color with the given channel maximum and minimum.
+func hue(r, g, b, max, min float64) (h float64) {
+	delta := max - min
+	switch {
+	case delta == 0:
+		return 0
+	case max == r:
+		h = math.Mod((g-b)/delta, 6)
+	case max == g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// colorFromHueChroma returns the opaque color with the hue in degrees and chroma c, adding m to each channel.
+func colorFromHueChroma(h, c, m float64) Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = c, x
+	case h < 120:
+		r, g = x, c
+	case h < 180:
+		g, b = c, x
+	case h < 240:
+		g, b = x, c
+	case h < 300:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	return Color{clampChannel((r + m) * 255), clampChannel((g + m) * 255), clampChannel((b + m) * 255), 0xFF}
+}
+
+// colorLab is a color in the CIELAB color space, using the D65 white point.
+type colorLab struct {
+	L, A, B float64
+}
+
+// ColorToLab returns the CIELAB lightness and a and b components of c, using the D65 white point.
+func ColorToLab(c Color) (l, a, b float64) {
+	lab := toLab(c)
+	return lab.L, lab.A, lab.B
+}
+
+// ColorFromLab returns the opaque color with the given CIELAB lightness and a and b components, clamped to sRGB.
+func ColorFromLab(l, a, b float64) Color {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+	f := func(t float64) float64 {
+		if t*t*t > 216.0/24389.0 {
+			return t * t * t
+		}
+		return (116*t - 16) * 27.0 / 24389.0
+	}
+	x, y, z := f(fx)*0.95047, f(fy), f(fz)*1.08883
+
+	gamma := func(v float64) uint8 {
+		if v <= 0.0031308 {
+			v *= 12.92
+		} else {
+			v = 1.055*math.Pow(v, 1/2.4) - 0.055
+		}
+		return clampChannel(v * 255)
+	}
+	return Color{
+		R: gamma(3.2404542*x - 1.5371385*y - 0.4985314*z),
+		G: gamma(-0.9692660*x + 1.8760108*y + 0.0415560*z),
+		B: gamma(0.0556434*x - 0.2040259*y + 1.0572252*z),
+		A: 0xFF,
+	}
+}
+
+// toLab converts an sRGB color to CIELAB.
+func toLab(c Color) colorLab {
+	linear := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.04045 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389.0 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27.0*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return colorLab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+// LerpColor returns the color t of the way from a to b, including alpha.
+func LerpColor(a, b Color, t float64) Color {
+	lerp := func(a, b uint8) uint8 {
+		return clampChannel(float64(a) + (float64(b)-float64(a))*t)
+	}
+	return Color{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A)}
+}
+
+// GradientStop is a color at an offset in [0, 1] along a Gradient.
+type GradientStop struct {
+	Offset float64
+	Color  Color
+}
+
+// Gradient is a multi-stop color gradient. Stops should be sorted by offset.
+type Gradient []GradientStop
+
+// NewGradient returns a Gradient with the colors evenly spaced from 0 to 1.
+func NewGradient(colors ...Color) Gradient {
+	gradient := make(Gradient, len(colors))
+	for i, c := range colors {
+		gradient[i].Color = c
+		if len(colors) > 1 {
+			gradient[i].Offset = float64(i) / float64(len(colors)-1)
+		}
+	}
+	return gradient
+}
+
+// At returns the color at t along the gradient, interpolating between the stops around it. An empty Gradient returns ColorNone.
+func (gradient Gradient) At(t float64) Color {
+	if len(gradient) == 0 {
+		return ColorNone
+	}
+	if t <= gradient[0].Offset {
+		return gradient[0].Color
+	}
+	last := gradient[len(gradient)-1]
+	if t >= last.Offset {
+		return last.Color
+	}
+	i := sort.Search(len(gradient), func(i int) bool {
+		return gradient[i].Offset > t
+	})
+	from, to := gradient[i-1], gradient[i]
+	return LerpColor(from.Color, to.Color, (t-from.Offset)/(to.Offset-from.Offset))
+}
+
+// BlendMode combines a top color onto a base color.
+type BlendMode func(base, top Color) Color
+
+// BlendMultiply multiplies the channels of the colors, darkening the base.
+func BlendMultiply(base, top Color) Color {
+	multiply := func(a, b uint8) uint8 {
+		return uint8((int(a)*int(b) + 127) / 255)
+	}
+	return Color{multiply(base.R, top.R), multiply(base.G, top.G), multiply(base.B, top.B), base.A}
+}
+
+// BlendScreen inverts, multiplies, and inverts again the channels of the colors, lightening the base.
+func BlendScreen(base, top Color) Color {
+	screen := func(a, b uint8) uint8 {
+		return 255 - uint8((int(255-a)*int(255-b)+127)/255)
+	}
+	return Color{screen(base.R, top.R), screen(base.G, top.G), screen(base.B, top.B), base.A}
+}
+
+// BlendAdd adds the channels of the colors, clamping at white.
+func BlendAdd(base, top Color) Color {
+	add := func(a, b uint8) uint8 {
+		return uint8(MinInt(int(a)+int(b), 255))
+	}
+	return Color{add(base.R, top.R), add(base.G, top.G), add(base.B, top.B), base.A}
+}
+
+// Blend combines top onto base using mode, then mixes the result with base by amount in [0, 1].
+func Blend(base, top Color, mode BlendMode, amount float64) Color {
+	return LerpColor(base, mode(base, top), amount)
+}
+
+// Brighten scales the RGB channels of c by factor. Factors below 1 darken.
+func Brighten(c Color, factor float64) Color {
+	return Color{clampChannel(float64(c.R) * factor), clampChannel(float64(c.G) * factor), clampChannel(float64(c.B) * factor), c.A}
+}
+
+// Desaturate moves c amount of the way, in [0, 1], towards the gray of the same luminance.
+func Desaturate(c Color, amount float64) Color {
+	luminance := clampChannel(0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B))
+	return LerpColor(c, Color{luminance, luminance, luminance, c.A}, amount)
+}
@@ -0,0 +1,127 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"math"
+)
+
+// colorDistance returns the squared perceptual distance between two colors in CIELAB space.
+func colorDistance(a, b Color) float64 {
+	return labDistance(toLab(a), toLab(b))
+}
+
+// labDistance returns the squared distance between two CIELAB colors.
+func labDistance(a, b colorLab) float64 {
+	dl, da, db := a.L-b.L, a.A-b.A, a.B-b.B
+	return dl*dl + da*da + db*db
+}
+
+// xtermPalette is the xterm 256 color palette: the 16 standard colors, the 6x6x6 color cube, and the grayscale ramp.
+var xtermPalette = func() (palette [256]Color) {
+	copy(palette[:], ansiPalette[:])
+	for i := 0; i < 216; i++ {
+		palette[16+i] = Color{R: xtermCubeLevels[i/36], G: xtermCubeLevels[i/6%6], B: xtermCubeLevels[i%6], A: 0xFF}
+	}
+	// The grayscale ramp runs from 0x08 to 0xEE in steps of 10.
+	for i := 0; i < 24; i++ {
+		level := uint8(8 + i*10)
+		palette[232+i] = Color{R: level, G: level, B: level, A: 0xFF}
+	}
+	return palette
+}()
+
+// xtermPaletteLab is xtermPalette converted to CIELAB.
+var xtermPaletteLab = func() (palette [256]colorLab) {
+	for i, c := range xtermPalette {
+		palette[i] = toLab(c)
+	}
+	return palette
+}()
+
+// xtermCubeLevels are the channel intensities of xterm's 6x6x6 color cube.
+var xtermCubeLevels = [6]uint8{0x00, 0x5F, 0x87, 0xAF, 0xD7, 0xFF}
+
+// nearestPaletteIndex returns the index of the color in xtermPalette[from:to] perceptually closest to c.
+func nearestPaletteIndex(c Color, from, to int) int {
+	lab := toLab(c)
+	best, bestDistance := from, -1.0
+	for i := from; i < to; i++ {
+		if d := labDistance(lab, xtermPaletteLab[i]); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+// quantizeColor returns the index of the palette color closest to c for the given mode. The 256 color mode skips the 16 standard colors, as terminals often theme them.
+func quantizeColor(c Color, mode ColorMode) int {
+	switch mode {
+	case ColorMode256:
+		return nearestPaletteIndex(c, 16, 256)
+	case ColorMode8:
+		return nearestPaletteIndex(c, 0, 8)
+	default:
+		return nearestPaletteIndex(c, 0, 16)
+	}
+}
+
+// bayerMatrix is the 4x4 ordered dithering threshold matrix.
+var bayerMatrix = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// ditherSpread returns how far ordered dithering may push a color channel in the given mode, roughly the distance between its palette colors.
+func ditherSpread(mode ColorMode) float64 {
+	switch mode {
+	case ColorMode256:
+		return 40
+	case ColorMode16:
+		return 96
+	case ColorMode8:
+		return 128
+	}
+	return 0
+}
+
+// ditherColor returns the palette color for c at the cell x and y, using ordered dithering so that neighboring cells approximate colors missing from the palette.
+func ditherColor(c Color, mode ColorMode, x, y int) Color {
+	offset := (float64(bayerMatrix[y&3][x&3])+0.5)/16 - 0.5
+	offset *= ditherSpread(mode)
+	channel := func(v uint8) uint8 {
+		return uint8(math.Max(0, math.Min(255, float64(v)+offset)))
+	}
+	return xtermPalette[quantizeColor(Color{R: channel(c.R), G: channel(c.G), B: channel(c.B), A: c.A}, mode)]
+}
+
+// colorModeFor returns the ColorMode for a terminal that supports the given number of colors.
+func colorModeFor(colors int) ColorMode {
+	switch {
+	case colors >= 1<<24:
+		return ColorModeTrueColor
+	case colors >= 256:
+		return ColorMode256
+	case colors >= 16:
+		return ColorMode16
+	}
+	return ColorMode8
+}
@@ -0,0 +1,345 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"math"
+)
+
+// Rect is a rectangle of cells.
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// Contains returns whether the cell at x and y is within the Rect.
+func (rect Rect) Contains(x, y int) bool {
+	return x >= rect.X && x < rect.X+rect.Width && y >= rect.Y && y < rect.Y+rect.Height
+}
+
+// Intersect returns the area shared by both Rects. If they do not overlap, the result is empty.
+func (rect Rect) Intersect(other Rect) Rect {
+	x0, y0 := MaxInt(rect.X, other.X), MaxInt(rect.Y, other.Y)
+	x1, y1 := MinInt(rect.X+rect.Width, other.X+other.Width), MinInt(rect.Y+rect.Height, other.Y+other.Height)
+	if x1 < x0 || y1 < y0 {
+		return Rect{X: x0, Y: y0}
+	}
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// Empty returns whether the Rect contains no cells.
+func (rect Rect) Empty() bool {
+	return rect.Width <= 0 || rect.Height <= 0
+}
+
+// PushClip limits drawing to the given rectangle, intersected with the current clip. Cells outside of the clip are silently left untouched by the Draw* and Set* methods.
+func (screen *Screen) PushClip(x, y, width, height int) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	clip := Rect{x, y, width, height}
+	if len(screen.clips) > 0 {
+		clip = clip.Intersect(screen.clips[len(screen.clips)-1])
+	}
+	screen.clips = append(screen.clips, clip)
+}
+
+// PopClip restores the clip that was in place before the last PushClip.
+func (screen *Screen) PopClip() {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	if len(screen.clips) > 0 {
+		screen.clips = screen.clips[:len(screen.clips)-1]
+	}
+}
+
+// Clip returns the current clip, and false if drawing is not clipped.
+func (screen *Screen) Clip() (Rect, bool) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	if len(screen.clips) == 0 {
+		return Rect{}, false
+	}
+	return screen.clips[len(screen.clips)-1], true
+}
+
+// inClip returns whether the cell at x and y may be drawn to. The cells lock must be held.
+func (screen *Screen) inClip(x, y int) bool {
+	return len(screen.clips) == 0 || screen.clips[len(screen.clips)-1].Contains(x, y)
+}
+
+// FillRect draws the rune with the style to every cell of the rectangle.
+func (screen *Screen) FillRect(x, y, width, height int, r rune, s Style) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	rect := screen.drawable(Rect{x, y, width, height})
+	for cy := rect.Y; cy < rect.Y+rect.Height; cy++ {
+		for cx := rect.X; cx < rect.X+rect.Width; cx++ {
+			screen.drawRune(cx, cy, r, s)
+		}
+	}
+}
+
+// drawable returns the part of rect within both the Screen and the current clip. The cells lock must be held.
+func (screen *Screen) drawable(rect Rect) Rect {
+	bounds := Rect{Height: len(screen.cells)}
+	if bounds.Height > 0 {
+		bounds.Width = len(screen.cells[0])
+	}
+	rect = rect.Intersect(bounds)
+	if len(screen.clips) > 0 {
+		rect = rect.Intersect(screen.clips[len(screen.clips)-1])
+	}
+	return rect
+}
+
+// Border is the set of runes used to draw a box.
+type Border struct {
+	Horizontal, Vertical                       rune
+	TopLeft, TopRight, BottomLeft, BottomRight rune
+	TitleLeft, TitleRight                      rune
+}
+
+// These are our box-drawing borders.
+var (
+	BorderSingle  = Border{'─', '│', '┌', '┐', '└', '┘', '┤', '├'}
+	BorderDouble  = Border{'═', '║', '╔', '╗', '╚', '╝', '╡', '╞'}
+	BorderHeavy   = Border{'━', '┃', '┏', '┓', '┗', '┛', '┫', '┣'}
+	BorderRounded = Border{'─', '│', '╭', '╮', '╰', '╯', '┤', '├'}
+)
+
+// DrawBox draws the outline of a rectangle using the border's runes. The inside is left untouched.
+func (screen *Screen) DrawBox(x, y, width, height int, border Border, s Style) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	right, bottom := x+width-1, y+height-1
+	for cx := x + 1; cx < right; cx++ {
+		screen.drawRune(cx, y, border.Horizontal, s)
+		screen.drawRune(cx, bottom, border.Horizontal, s)
+	}
+	for cy := y + 1; cy < bottom; cy++ {
+		screen.drawRune(x, cy, border.Vertical, s)
+		screen.drawRune(right, cy, border.Vertical, s)
+	}
+	screen.drawRune(x, y, border.TopLeft, s)
+	screen.drawRune(right, y, border.TopRight, s)
+	screen.drawRune(x, bottom, border.BottomLeft, s)
+	screen.drawRune(right, bottom, border.BottomRight, s)
+}
+
+// DrawFrame draws a box with the title set into its top edge, truncated to fit.
+func (screen *Screen) DrawFrame(x, y, width, height int, border Border, s Style, title string) {
+	screen.DrawBox(x, y, width, height, border, s)
+	runes := []rune(title)
+	// The title needs room for the corners and its own end caps.
+	space := width - 4
+	if len(runes) == 0 || space <= 0 {
+		return
+	}
+	if len(runes) > space {
+		runes = runes[:space]
+	}
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	screen.drawRune(x+1, y, border.TitleLeft, s)
+	for i, r := range runes {
+		screen.drawRune(x+2+i, y, r, s)
+	}
+	screen.drawRune(x+2+len(runes), y, border.TitleRight, s)
+}
+
+// DrawLine draws the rune with the style along the line from x0, y0 to x1, y1 using Bresenham's algorithm.
+func (screen *Screen) DrawLine(x0, y0, x1, y1 int, r rune, s Style) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	dx, dy := x1-x0, -(y1 - y0)
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy > 0 {
+		dy = -dy
+	}
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		screen.drawRune(x0, y0, r, s)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// DrawLineAA blends the color into the backgrounds of the cells along the line from x0, y0 to x1, y1 using Xiaolin Wu's algorithm, so that the line appears smooth. Coordinates are in cells, with whole numbers at cell centers.
+func (screen *Screen) DrawLineAA(x0, y0, x1, y1 float64, c Color) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	steep := math.Abs(y1-y0) > math.Abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, x1, y0, y1 = x1, x0, y1, y0
+	}
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			x, y = y, x
+		}
+		screen.blendBackground(x, y, c, coverage)
+	}
+	gradient := 1.0
+	if dx := x1 - x0; dx != 0 {
+		gradient = (y1 - y0) / dx
+	}
+
+	start, end := int(math.Round(x0)), int(math.Round(x1))
+	y := y0 + gradient*(float64(start)-x0)
+	for x := start; x <= end; x++ {
+		base := math.Floor(y)
+		fraction := y - base
+		plot(x, int(base), 1-fraction)
+		if fraction > 0 {
+			plot(x, int(base)+1, fraction)
+		}
+		y += gradient
+	}
+}
+
+// blendBackground blends the color into the background at x and y by amount. A cell without a background is blended from the Screen's default background, or black. The cells lock must be held.
+func (screen *Screen) blendBackground(x, y int, c Color, amount float64) {
+	if screen.checkBounds(x, y) != nil {
+		return
+	}
+	s := screen.cells[y][x].PendingStyle
+	background := s.Background
+	if background == ColorNone {
+		background = screen.Background
+	}
+	if background == ColorNone {
+		background = ColorBlack
+	}
+	s.Background = LerpColor(background, c, amount)
+	screen.setStyle(x, y, s)
+}
+
+// DrawEllipse draws the rune with the style along the outline of the ellipse centered at cx and cy with the horizontal and vertical radii rx and ry.
+func (screen *Screen) DrawEllipse(cx, cy, rx, ry int, r rune, s Style) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	// Stepping along both axes leaves no gaps where the outline is steep or shallow.
+	for dy := -ry; dy <= ry; dy++ {
+		dx := ellipseExtent(rx, ry, dy)
+		screen.drawRune(cx-dx, cy+dy, r, s)
+		screen.drawRune(cx+dx, cy+dy, r, s)
+	}
+	for dx := -rx; dx <= rx; dx++ {
+		dy := ellipseExtent(ry, rx, dx)
+		screen.drawRune(cx+dx, cy-dy, r, s)
+		screen.drawRune(cx+dx, cy+dy, r, s)
+	}
+}
+
+// FillEllipse draws the rune with the style to every cell within the ellipse centered at cx and cy with the horizontal and vertical radii rx and ry.
+func (screen *Screen) FillEllipse(cx, cy, rx, ry int, r rune, s Style) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	for dy := -ry; dy <= ry; dy++ {
+		dx := ellipseExtent(rx, ry, dy)
+		for x := cx - dx; x <= cx+dx; x++ {
+			screen.drawRune(x, cy+dy, r, s)
+		}
+	}
+}
+
+// DrawCircle draws the outline of a circle with the given radius in cells.
+func (screen *Screen) DrawCircle(cx, cy, radius int, r rune, s Style) {
+	screen.DrawEllipse(cx, cy, radius, radius, r, s)
+}
+
+// FillCircle fills a circle with the given radius in cells.
+func (screen *Screen) FillCircle(cx, cy, radius int, r rune, s Style) {
+	screen.FillEllipse(cx, cy, radius, radius, r, s)
+}
+
+// ellipseExtent returns how far the ellipse with radii a and b extends along the first axis at offset d along the second.
+func ellipseExtent(a, b, d int) int {
+	if b == 0 {
+		return a
+	}
+	t := float64(d) / float64(b)
+	return int(math.Round(float64(a) * math.Sqrt(math.Max(0, 1-t*t))))
+}
+
+// FloodMatch selects which parts of a cell FloodFill compares to decide if it belongs to the filled region.
+type FloodMatch uint8
+
+// These are our flood fill matching modes.
+const (
+	FloodMatchRune FloodMatch = 1 << iota
+	FloodMatchStyle
+	FloodMatchAll = FloodMatchRune | FloodMatchStyle
+)
+
+// FloodFill draws the rune with the style to the cell at x and y and every cell connected to it horizontally or vertically that matches it, as selected by match. The fill does not spread outside of the clip.
+func (screen *Screen) FloodFill(x, y int, match FloodMatch, r rune, s Style) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	if err := screen.checkBounds(x, y); err != nil {
+		return err
+	}
+	target := screen.cells[y][x]
+	matches := func(cell *Cell) bool {
+		if match&FloodMatchRune != 0 && cell.PendingRune != target.PendingRune {
+			return false
+		}
+		if match&FloodMatchStyle != 0 && cell.PendingStyle != target.PendingStyle {
+			return false
+		}
+		return true
+	}
+
+	visited := make(map[[2]int]bool)
+	stack := [][2]int{{x, y}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[p] || screen.checkBounds(p[0], p[1]) != nil || !screen.inClip(p[0], p[1]) || !matches(&screen.cells[p[1]][p[0]]) {
+			continue
+		}
+		visited[p] = true
+		screen.drawRune(p[0], p[1], r, s)
+		stack = append(stack, [2]int{p[0] + 1, p[1]}, [2]int{p[0] - 1, p[1]}, [2]int{p[0], p[1] + 1}, [2]int{p[0], p[1] - 1})
+	}
+	return nil
+}
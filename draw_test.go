@@ -0,0 +1,235 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestScreen returns a virtual Screen of the given size.
+func newTestScreen(t *testing.T, columns, rows int) *Screen {
+	screen, err := NewScreen(columns, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return screen
+}
+
+// screenRunes returns the pending runes of the Screen as one string per row, with undrawn cells as '.'.
+func screenRunes(screen *Screen) []string {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	rows := make([]string, len(screen.cells))
+	for y := range screen.cells {
+		var row strings.Builder
+		for x := range screen.cells[y] {
+			r := screen.cells[y][x].PendingRune
+			if r == 0 {
+				r = '.'
+			}
+			row.WriteRune(r)
+		}
+		rows[y] = row.String()
+	}
+	return rows
+}
+
+// drawRunes draws each row of runes onto the Screen, skipping '.'.
+func drawRunes(screen *Screen, rows []string) {
+	for y, row := range rows {
+		for x, r := range []rune(row) {
+			if r != '.' {
+				screen.DrawRune(x, y, r, Style{})
+			}
+		}
+	}
+}
+
+func TestFillRect(t *testing.T) {
+	tests := []struct {
+		name                string
+		x, y, width, height int
+		clip                *Rect
+		want                []string
+	}{
+		{"inside", 1, 1, 2, 2, nil, []string{"....", ".##.", ".##.", "...."}},
+		{"overlapping the top left", -2, -2, 4, 3, nil, []string{"##..", "....", "....", "...."}},
+		{"overlapping the bottom right", 2, 3, 5, 5, nil, []string{"....", "....", "....", "..##"}},
+		{"outside", 5, 0, 2, 2, nil, []string{"....", "....", "....", "...."}},
+		{"negative size", 2, 2, -1, -1, nil, []string{"....", "....", "....", "...."}},
+		{"huge", -1 << 30, -1 << 30, 1 << 31, 1 << 31, nil, []string{"####", "####", "####", "####"}},
+		{"clipped", 0, 0, 4, 4, &Rect{1, 0, 2, 3}, []string{".##.", ".##.", ".##.", "...."}},
+		{"outside the clip", 0, 0, 1, 1, &Rect{2, 2, 2, 2}, []string{"....", "....", "....", "...."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen := newTestScreen(t, 4, 4)
+			if test.clip != nil {
+				screen.PushClip(test.clip.X, test.clip.Y, test.clip.Width, test.clip.Height)
+			}
+			screen.FillRect(test.x, test.y, test.width, test.height, '#', Style{})
+			if got := screenRunes(screen); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDrawLine(t *testing.T) {
+	tests := []struct {
+		name           string
+		x0, y0, x1, y1 int
+		want           []string
+	}{
+		{"point", 2, 1, 2, 1, []string{".....", "..#..", ".....", "....."}},
+		{"horizontal", 0, 1, 4, 1, []string{".....", "#####", ".....", "....."}},
+		{"vertical", 3, 3, 3, 0, []string{"...#.", "...#.", "...#.", "...#."}},
+		{"diagonal", 0, 0, 3, 3, []string{"#....", ".#...", "..#..", "...#."}},
+		{"shallow", 0, 0, 4, 2, []string{"#....", ".##..", "...##", "....."}},
+		{"shallow reversed", 4, 2, 0, 0, []string{"##...", "..##.", "....#", "....."}},
+		{"steep", 0, 3, 1, 0, []string{".#...", ".#...", "#....", "#...."}},
+		{"off the screen", -2, 1, 6, 1, []string{".....", "#####", ".....", "....."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen := newTestScreen(t, 5, 4)
+			screen.DrawLine(test.x0, test.y0, test.x1, test.y1, '#', Style{})
+			got := screenRunes(screen)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			for _, end := range [][2]int{{test.x0, test.y0}, {test.x1, test.y1}} {
+				if screen.checkBounds(end[0], end[1]) == nil && got[end[1]][end[0]] != '#' {
+					t.Errorf("endpoint %d,%d not drawn", end[0], end[1])
+				}
+			}
+		})
+	}
+}
+
+func TestDrawEllipse(t *testing.T) {
+	tests := []struct {
+		name           string
+		cx, cy, rx, ry int
+		want           []string
+	}{
+		{"point", 2, 2, 0, 0, []string{".....", ".....", "..#..", ".....", "....."}},
+		{"radius 1", 2, 2, 1, 1, []string{".....", "..#..", ".#.#.", "..#..", "....."}},
+		{"radius 2", 2, 2, 2, 2, []string{".###.", "#...#", "#...#", "#...#", ".###."}},
+		{"wide", 2, 2, 2, 1, []string{".....", ".###.", "#...#", ".###.", "....."}},
+		{"flat", 2, 2, 2, 0, []string{".....", ".....", "#####", ".....", "....."}},
+		{"off the screen", 0, 0, 1, 1, []string{".#...", "#....", ".....", ".....", "....."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen := newTestScreen(t, 5, 5)
+			screen.DrawEllipse(test.cx, test.cy, test.rx, test.ry, '#', Style{})
+			if got := screenRunes(screen); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	walls := []string{
+		"..#..",
+		"..#..",
+		"###..",
+		".....",
+	}
+	tests := []struct {
+		name  string
+		x, y  int
+		r     rune
+		clip  *Rect
+		want  []string
+		error bool
+	}{
+		{"enclosed", 0, 0, 'o', nil, []string{"oo#..", "oo#..", "###..", "....."}, false},
+		{"open", 4, 0, 'o', nil, []string{"..#oo", "..#oo", "###oo", "ooooo"}, false},
+		{"walls", 2, 2, 'o', nil, []string{"..o..", "..o..", "ooo..", "....."}, false},
+		{"same as the target", 4, 0, 0, nil, walls, false},
+		{"clipped", 4, 3, 'o', &Rect{3, 1, 2, 3}, []string{"..#..", "..#oo", "###oo", "...oo"}, false},
+		{"out of bounds", 5, 0, 'o', nil, walls, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen := newTestScreen(t, 5, 4)
+			drawRunes(screen, walls)
+			if test.clip != nil {
+				screen.PushClip(test.clip.X, test.clip.Y, test.clip.Width, test.clip.Height)
+			}
+			err := screen.FloodFill(test.x, test.y, FloodMatchRune, test.r, Style{})
+			if (err != nil) != test.error {
+				t.Fatalf("got error %v, want error %v", err, test.error)
+			}
+			if got := screenRunes(screen); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestClip(t *testing.T) {
+	screen := newTestScreen(t, 6, 4)
+	if _, ok := screen.Clip(); ok {
+		t.Fatal("new screen is clipped")
+	}
+
+	steps := []struct {
+		name string
+		push *Rect
+		want Rect
+		ok   bool
+	}{
+		{"push", &Rect{1, 0, 4, 3}, Rect{1, 0, 4, 3}, true},
+		{"push intersecting", &Rect{3, 1, 5, 5}, Rect{3, 1, 2, 2}, true},
+		{"push disjoint", &Rect{0, 3, 1, 1}, Rect{X: 3, Y: 3}, true},
+		{"pop disjoint", nil, Rect{3, 1, 2, 2}, true},
+		{"pop intersecting", nil, Rect{1, 0, 4, 3}, true},
+		{"pop", nil, Rect{}, false},
+		{"pop empty", nil, Rect{}, false},
+	}
+	for _, step := range steps {
+		if step.push != nil {
+			screen.PushClip(step.push.X, step.push.Y, step.push.Width, step.push.Height)
+		} else {
+			screen.PopClip()
+		}
+		clip, ok := screen.Clip()
+		if clip != step.want || ok != step.ok {
+			t.Errorf("%s: got %v %v, want %v %v", step.name, clip, ok, step.want, step.ok)
+		}
+	}
+
+	screen.PushClip(1, 1, 3, 2)
+	screen.PushClip(2, 0, 4, 4)
+	screen.DrawLine(0, 1, 5, 1, '#', Style{})
+	screen.PopClip()
+	screen.DrawLine(0, 2, 5, 2, '#', Style{})
+	screen.PopClip()
+	screen.DrawRune(0, 3, '#', Style{})
+	want := []string{"......", "..##..", ".###..", "#....."}
+	if got := screenRunes(screen); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package ecs
+
+import (
+	"github.com/kettek/goro"
+	"github.com/kettek/goro/fov"
+	"github.com/kettek/goro/pathing"
+)
+
+// Position is an entity's location in cells.
+type Position struct {
+	X, Y int
+}
+
+// Renderable is drawn by RenderSystem at the entity's Position. Renderables with a higher Z are drawn over those with a lower Z.
+type Renderable struct {
+	Rune  rune
+	Style goro.Style
+	Z     int
+}
+
+// Viewer gives an entity a field of view, which FOVSystem computes from its Position whenever it moves, its Cone changes, or Dirty is set.
+type Viewer struct {
+	Map    fov.Map
+	Radius int
+	Light  fov.Light
+	Cone   fov.Cone
+	Dirty  bool
+
+	lastX, lastY int
+	lastCone     fov.Cone
+	computed     bool
+}
+
+// CanSee returns whether the viewer's field of view contains the cell at x and y.
+func (viewer *Viewer) CanSee(x, y int) bool {
+	return viewer.computed && viewer.Map.Visible(x, y)
+}
+
+// Memory records what a Viewer entity last saw at each explored cell, so RenderSystem can draw it under fog of war.
+type Memory struct {
+	Remembered map[Position]Renderable
+}
+
+// Forget clears everything the entity remembers.
+func (memory *Memory) Forget() {
+	memory.Remembered = nil
+}
+
+// Mover moves an entity along Path toward a target, one step per update of MovementSystem.
+type Mover struct {
+	Path pathing.Path
+
+	targetX, targetY int
+	hasTarget        bool
+	steps            []pathing.Step
+}
+
+// MoveTo sets the cell the entity moves toward.
+func (mover *Mover) MoveTo(x, y int) {
+	mover.targetX, mover.targetY = x, y
+	mover.hasTarget = true
+	mover.steps = nil
+}
+
+// Stop clears the mover's target.
+func (mover *Mover) Stop() {
+	mover.hasTarget = false
+	mover.steps = nil
+}
+
+// Moving returns whether the mover has a target it has not reached.
+func (mover *Mover) Moving() bool {
+	return mover.hasTarget
+}
+
+// Steps returns the remaining steps to the target.
+func (mover *Mover) Steps() []pathing.Step {
+	return mover.steps
+}
+
+// BlocksMovement marks an entity as occupying its cell, preventing Movers from entering it.
+type BlocksMovement struct{}
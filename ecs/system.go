@@ -0,0 +1,71 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package ecs
+
+import (
+	"reflect"
+	"sort"
+)
+
+// System updates the entities of a World.
+type System interface {
+	Update(world *World)
+}
+
+// SystemFunc is a function that acts as a System.
+type SystemFunc func(world *World)
+
+// Update calls the function.
+func (f SystemFunc) Update(world *World) {
+	f(world)
+}
+
+// systemEntry is a System and its order within a World.
+type systemEntry struct {
+	system   System
+	priority int
+}
+
+// AddSystem adds the system to be updated by Update. Systems with a lower priority are updated first, and systems with the same priority are updated in the order they were added.
+func (world *World) AddSystem(system System, priority int) {
+	world.systems = append(world.systems, systemEntry{system: system, priority: priority})
+	sort.SliceStable(world.systems, func(i, j int) bool {
+		return world.systems[i].priority < world.systems[j].priority
+	})
+}
+
+// RemoveSystem removes the system. Systems that cannot be compared, such as SystemFuncs, cannot be removed.
+func (world *World) RemoveSystem(system System) {
+	if !reflect.TypeOf(system).Comparable() {
+		return
+	}
+	for i, entry := range world.systems {
+		if entry.system == system {
+			world.systems = append(world.systems[:i], world.systems[i+1:]...)
+			return
+		}
+	}
+}
+
+// Update updates each system in order.
+func (world *World) Update() {
+	for _, entry := range append([]systemEntry(nil), world.systems...) {
+		entry.system.Update(world)
+	}
+}
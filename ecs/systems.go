@@ -0,0 +1,199 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package ecs
+
+import (
+	"sort"
+
+	"github.com/kettek/goro"
+	"github.com/kettek/goro/fov"
+)
+
+// FOVSystem computes the field of view of each entity with a Viewer and Position.
+type FOVSystem struct{}
+
+// Update recomputes the fields of view of viewers that have moved or are dirty.
+func (system *FOVSystem) Update(world *World) {
+	for _, entity := range world.Query((*Viewer)(nil), (*Position)(nil)) {
+		var viewer *Viewer
+		var position *Position
+		world.Get(entity, &viewer)
+		world.Get(entity, &position)
+		if viewer.Map == nil {
+			continue
+		}
+		if viewer.computed && !viewer.Dirty && viewer.lastX == position.X && viewer.lastY == position.Y && viewer.lastCone == viewer.Cone {
+			continue
+		}
+		fov.RecomputeCone(viewer.Map, position.X, position.Y, viewer.Radius, viewer.Light, viewer.Cone)
+		viewer.lastX, viewer.lastY = position.X, position.Y
+		viewer.lastCone = viewer.Cone
+		viewer.computed = true
+		viewer.Dirty = false
+	}
+}
+
+// MovementSystem moves each entity with a Mover and Position one step toward its target. Paths are computed when a target is set and recomputed when the next step is occupied by an entity with BlocksMovement.
+type MovementSystem struct{}
+
+// Update moves each mover one step.
+func (system *MovementSystem) Update(world *World) {
+	occupied := make(map[Position]Entity)
+	for _, entity := range world.Query((*BlocksMovement)(nil), (*Position)(nil)) {
+		var position *Position
+		world.Get(entity, &position)
+		occupied[*position] = entity
+	}
+
+	for _, entity := range world.Query((*Mover)(nil), (*Position)(nil)) {
+		var mover *Mover
+		var position *Position
+		world.Get(entity, &mover)
+		world.Get(entity, &position)
+		if !mover.hasTarget || mover.Path == nil {
+			continue
+		}
+		if position.X == mover.targetX && position.Y == mover.targetY {
+			mover.Stop()
+			continue
+		}
+		if len(mover.steps) == 0 {
+			mover.steps = mover.Path.Compute(position.X, position.Y, mover.targetX, mover.targetY)
+			if len(mover.steps) == 0 {
+				// The target cannot be reached.
+				mover.Stop()
+				continue
+			}
+		}
+		next := Position{mover.steps[0].X(), mover.steps[0].Y()}
+		if blocker, ok := occupied[next]; ok && blocker != entity {
+			// Wait for the cell to clear, recomputing the path next update in case there is a way around.
+			mover.steps = nil
+			continue
+		}
+		if _, ok := occupied[*position]; ok && world.Has(entity, (*BlocksMovement)(nil)) {
+			delete(occupied, *position)
+			occupied[next] = entity
+		}
+		*position = next
+		mover.steps = mover.steps[1:]
+		if len(mover.steps) == 0 {
+			mover.Stop()
+		}
+	}
+}
+
+// RenderSystem draws each entity with a Renderable and Position to Screen, offset by OffsetX and OffsetY. If Viewer is set to an entity with a Viewer component, only entities that entity can see are drawn. If that entity also has a Memory and Fog is set, what it last saw at explored cells it cannot currently see is drawn with FogStyle.
+type RenderSystem struct {
+	Screen           *goro.Screen
+	Viewer           Entity
+	OffsetX, OffsetY int
+	Fog              bool
+	FogStyle         func(goro.Style) goro.Style
+}
+
+// DefaultFogStyle desaturates and darkens a remembered cell's colors.
+func DefaultFogStyle(s goro.Style) goro.Style {
+	if s.Foreground != goro.ColorNone {
+		s.Foreground = goro.Brighten(goro.Desaturate(s.Foreground, 0.8), 0.5)
+	}
+	if s.Background != goro.ColorNone {
+		s.Background = goro.Brighten(goro.Desaturate(s.Background, 0.8), 0.5)
+	}
+	return s
+}
+
+// Update draws the renderables in order of their Z.
+func (system *RenderSystem) Update(world *World) {
+	if system.Screen == nil {
+		return
+	}
+	var viewer *Viewer
+	var memory *Memory
+	if system.Viewer != 0 {
+		world.Get(system.Viewer, &viewer)
+		world.Get(system.Viewer, &memory)
+	}
+
+	entities := world.Query((*Renderable)(nil), (*Position)(nil))
+	renderables := make([]*Renderable, len(entities))
+	positions := make([]*Position, len(entities))
+	for i, entity := range entities {
+		world.Get(entity, &renderables[i])
+		world.Get(entity, &positions[i])
+	}
+	order := make([]int, len(entities))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return renderables[order[i]].Z < renderables[order[j]].Z
+	})
+
+	if viewer != nil && viewer.Map != nil && memory != nil {
+		system.remember(viewer, memory, renderables, positions, order)
+		if system.Fog {
+			fogStyle := system.FogStyle
+			if fogStyle == nil {
+				fogStyle = DefaultFogStyle
+			}
+			for position, renderable := range memory.Remembered {
+				if remembered(viewer, position.X, position.Y) {
+					system.Screen.DrawRune(position.X+system.OffsetX, position.Y+system.OffsetY, renderable.Rune, fogStyle(renderable.Style))
+				}
+			}
+		}
+	}
+
+	for _, i := range order {
+		position := positions[i]
+		if viewer != nil && !viewer.CanSee(position.X, position.Y) {
+			continue
+		}
+		system.Screen.DrawRune(position.X+system.OffsetX, position.Y+system.OffsetY, renderables[i].Rune, renderables[i].Style)
+	}
+}
+
+// remembered returns whether the viewer remembers the cell at x and y rather than seeing it. Maps that are not a fov.MemoryMap are taken to remember every cell the viewer cannot see.
+func remembered(viewer *Viewer, x, y int) bool {
+	if memoryMap, ok := viewer.Map.(fov.MemoryMap); ok {
+		return memoryMap.Remembered(x, y)
+	}
+	return !viewer.CanSee(x, y)
+}
+
+// remember updates memory with the topmost renderable at each cell the viewer can see, forgetting cells that are now seen to be empty.
+func (system *RenderSystem) remember(viewer *Viewer, memory *Memory, renderables []*Renderable, positions []*Position, order []int) {
+	for position := range memory.Remembered {
+		if viewer.CanSee(position.X, position.Y) {
+			delete(memory.Remembered, position)
+		}
+	}
+	for _, i := range order {
+		position := positions[i]
+		if !viewer.CanSee(position.X, position.Y) {
+			continue
+		}
+		if memory.Remembered == nil {
+			memory.Remembered = make(map[Position]Renderable)
+		}
+		// Renderables are in order of Z, so later ones replace those beneath them.
+		memory.Remembered[*position] = *renderables[i]
+	}
+}
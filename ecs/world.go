@@ -0,0 +1,186 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package ecs provides an entity-component-system for organizing game state, with built-in components and systems for positions, rendering to a goro.Screen, field of view, and pathing.
+package ecs
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// ErrNoEntity is returned when an entity does not exist in the World.
+var ErrNoEntity = errors.New("entity does not exist")
+
+// Entity identifies an entity within a World. The zero Entity is never used.
+type Entity uint64
+
+// World holds entities, their components, and the systems that update them.
+type World struct {
+	next       Entity
+	entities   map[Entity]struct{}
+	components map[reflect.Type]map[Entity]interface{}
+	systems    []systemEntry
+}
+
+// NewWorld returns an empty World.
+func NewWorld() *World {
+	return &World{
+		entities:   make(map[Entity]struct{}),
+		components: make(map[reflect.Type]map[Entity]interface{}),
+	}
+}
+
+// NewEntity creates an entity with the given components.
+func (world *World) NewEntity(components ...interface{}) Entity {
+	world.next++
+	entity := world.next
+	world.entities[entity] = struct{}{}
+	for _, component := range components {
+		world.Add(entity, component)
+	}
+	return entity
+}
+
+// Destroy removes the entity and its components.
+func (world *World) Destroy(entity Entity) {
+	delete(world.entities, entity)
+	for _, store := range world.components {
+		delete(store, entity)
+	}
+}
+
+// Alive returns whether the entity exists.
+func (world *World) Alive(entity Entity) bool {
+	_, ok := world.entities[entity]
+	return ok
+}
+
+// Entities returns every entity in the order they were created.
+func (world *World) Entities() []Entity {
+	entities := make([]Entity, 0, len(world.entities))
+	for entity := range world.entities {
+		entities = append(entities, entity)
+	}
+	sortEntities(entities)
+	return entities
+}
+
+// Add adds the component to the entity, replacing any component of the same type. Components are stored by their type, so pointers to structs, such as *Position, allow them to be modified in place.
+func (world *World) Add(entity Entity, component interface{}) error {
+	if !world.Alive(entity) {
+		return ErrNoEntity
+	}
+	componentType := reflect.TypeOf(component)
+	store, ok := world.components[componentType]
+	if !ok {
+		store = make(map[Entity]interface{})
+		world.components[componentType] = store
+	}
+	store[entity] = component
+	return nil
+}
+
+// Remove removes the entity's component of the same type as component, which may be a nil pointer such as (*Position)(nil).
+func (world *World) Remove(entity Entity, component interface{}) {
+	if store, ok := world.components[reflect.TypeOf(component)]; ok {
+		delete(store, entity)
+	}
+}
+
+// Has returns whether the entity has a component of the same type as component, which may be a nil pointer such as (*Position)(nil).
+func (world *World) Has(entity Entity, component interface{}) bool {
+	if store, ok := world.components[reflect.TypeOf(component)]; ok {
+		_, ok = store[entity]
+		return ok
+	}
+	return false
+}
+
+// Get stores the entity's component in target, which must be a pointer to a variable of the component's type, and returns whether the entity has it.
+//
+//	var position *ecs.Position
+//	if world.Get(entity, &position) { ... }
+func (world *World) Get(entity Entity, target interface{}) bool {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return false
+	}
+	store, ok := world.components[value.Type().Elem()]
+	if !ok {
+		return false
+	}
+	component, ok := store[entity]
+	if !ok {
+		return false
+	}
+	value.Elem().Set(reflect.ValueOf(component))
+	return true
+}
+
+// Component returns the entity's component of the same type as component, which may be a nil pointer such as (*Position)(nil), or nil if it has none.
+func (world *World) Component(entity Entity, component interface{}) interface{} {
+	if store, ok := world.components[reflect.TypeOf(component)]; ok {
+		return store[entity]
+	}
+	return nil
+}
+
+// Query returns the entities that have a component of each of the types of components, in the order they were created.
+//
+//	for _, entity := range world.Query((*ecs.Position)(nil), (*ecs.Renderable)(nil)) { ... }
+func (world *World) Query(components ...interface{}) []Entity {
+	if len(components) == 0 {
+		return world.Entities()
+	}
+	stores := make([]map[Entity]interface{}, len(components))
+	for i, component := range components {
+		store, ok := world.components[reflect.TypeOf(component)]
+		if !ok {
+			return nil
+		}
+		stores[i] = store
+	}
+	// Iterate over the smallest store.
+	sort.Slice(stores, func(i, j int) bool {
+		return len(stores[i]) < len(stores[j])
+	})
+	var entities []Entity
+	for entity := range stores[0] {
+		matches := true
+		for _, store := range stores[1:] {
+			if _, ok := store[entity]; !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			entities = append(entities, entity)
+		}
+	}
+	sortEntities(entities)
+	return entities
+}
+
+// sortEntities sorts entities into the order they were created.
+func sortEntities(entities []Entity) {
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i] < entities[j]
+	})
+}
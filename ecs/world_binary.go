@@ -0,0 +1,151 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package ecs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"sort"
+
+	"github.com/kettek/goro/fov"
+	"github.com/kettek/goro/pathing"
+)
+
+func init() {
+	Register(&Position{})
+	Register(&Renderable{})
+	Register(&Viewer{})
+	Register(&Memory{})
+	Register(&Mover{})
+	Register(&BlocksMovement{})
+	gob.Register(&fov.MapBBQ{})
+	gob.Register(&pathing.PathAStar{})
+}
+
+// Register registers a component type so that Worlds containing it can be encoded with MarshalBinary. Components are encoded with encoding/gob, so their exported fields are saved.
+func Register(component interface{}) {
+	gob.Register(component)
+}
+
+// worldState is the serialized form of a World.
+type worldState struct {
+	Next       Entity
+	Entities   []Entity
+	Components []componentState
+}
+
+// componentState is a single serialized component.
+type componentState struct {
+	Entity    Entity
+	Component interface{}
+}
+
+// MarshalBinary encodes the World's entities and components. Systems are not encoded. Every component type must have been registered with Register.
+func (world *World) MarshalBinary() ([]byte, error) {
+	state := worldState{
+		Next:     world.next,
+		Entities: world.Entities(),
+	}
+	types := make([]reflect.Type, 0, len(world.components))
+	for componentType := range world.components {
+		types = append(types, componentType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].String() < types[j].String()
+	})
+	for _, componentType := range types {
+		store := world.components[componentType]
+		entities := make([]Entity, 0, len(store))
+		for entity := range store {
+			entities = append(entities, entity)
+		}
+		sortEntities(entities)
+		for _, entity := range entities {
+			state.Components = append(state.Components, componentState{Entity: entity, Component: store[entity]})
+		}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces the World's entities and components with those encoded by MarshalBinary. Systems are kept.
+func (world *World) UnmarshalBinary(data []byte) error {
+	var state worldState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	world.next = state.Next
+	world.entities = make(map[Entity]struct{}, len(state.Entities))
+	world.components = make(map[reflect.Type]map[Entity]interface{})
+	for _, entity := range state.Entities {
+		world.entities[entity] = struct{}{}
+	}
+	for _, component := range state.Components {
+		world.Add(component.Entity, component.Component)
+	}
+	return nil
+}
+
+// moverState is the serialized form of a Mover.
+type moverState struct {
+	Path             pathing.Path
+	TargetX, TargetY int
+	HasTarget        bool
+	Steps            []pathing.Step
+}
+
+// GobEncode encodes the mover, including its target and remaining steps.
+func (mover *Mover) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(moverState{
+		Path:      mover.Path,
+		TargetX:   mover.targetX,
+		TargetY:   mover.targetY,
+		HasTarget: mover.hasTarget,
+		Steps:     mover.steps,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode decodes a mover encoded by GobEncode.
+func (mover *Mover) GobDecode(data []byte) error {
+	var state moverState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	mover.Path = state.Path
+	mover.targetX, mover.targetY = state.TargetX, state.TargetY
+	mover.hasTarget = state.HasTarget
+	mover.steps = state.Steps
+	return nil
+}
+
+// GobEncode encodes nothing, as BlocksMovement has no fields.
+func (blocks *BlocksMovement) GobEncode() ([]byte, error) {
+	return []byte{}, nil
+}
+
+// GobDecode does nothing, as BlocksMovement has no fields.
+func (blocks *BlocksMovement) GobDecode(data []byte) error {
+	return nil
+}
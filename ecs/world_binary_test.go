@@ -0,0 +1,198 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package ecs
+
+import (
+	"encoding"
+	"reflect"
+	"testing"
+
+	"github.com/kettek/goro"
+	"github.com/kettek/goro/fov"
+	"github.com/kettek/goro/pathing"
+)
+
+// health is a component registered by the test rather than the package.
+type health struct {
+	Current, Max int
+}
+
+// unregistered is a component that is never registered.
+type unregistered struct {
+	Value int
+}
+
+func init() {
+	Register(&health{})
+}
+
+// binaryEqual returns whether a and b have the same binary encoding.
+func binaryEqual(t *testing.T, a, b encoding.BinaryMarshaler) bool {
+	dataA, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataB, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(dataA, dataB)
+}
+
+// compareWorlds reports differences between the entities and components of two worlds.
+func compareWorlds(t *testing.T, got, want *World) {
+	if !reflect.DeepEqual(got.Entities(), want.Entities()) {
+		t.Fatalf("got entities %v, want %v", got.Entities(), want.Entities())
+	}
+	if got.next != want.next {
+		t.Errorf("got next entity %d, want %d", got.next, want.next)
+	}
+	if len(got.components) != len(want.components) {
+		t.Errorf("got %d component types, want %d", len(got.components), len(want.components))
+	}
+	for componentType, store := range want.components {
+		for entity, wantComponent := range store {
+			gotComponent, ok := got.components[componentType][entity]
+			if !ok {
+				t.Errorf("entity %d is missing its %v", entity, componentType)
+				continue
+			}
+			switch wantComponent := wantComponent.(type) {
+			case *Viewer:
+				gotViewer := gotComponent.(*Viewer)
+				if gotViewer.Radius != wantComponent.Radius || gotViewer.Light != wantComponent.Light || gotViewer.Cone != wantComponent.Cone || gotViewer.Dirty != wantComponent.Dirty {
+					t.Errorf("entity %d: got viewer %+v, want %+v", entity, gotViewer, wantComponent)
+				}
+				if !binaryEqual(t, gotViewer.Map.(*fov.MapBBQ), wantComponent.Map.(*fov.MapBBQ)) {
+					t.Errorf("entity %d: viewer maps differ", entity)
+				}
+			case *Mover:
+				gotMover := gotComponent.(*Mover)
+				if gotMover.targetX != wantComponent.targetX || gotMover.targetY != wantComponent.targetY || gotMover.hasTarget != wantComponent.hasTarget || !reflect.DeepEqual(gotMover.steps, wantComponent.steps) {
+					t.Errorf("entity %d: got mover %+v, want %+v", entity, gotMover, wantComponent)
+				}
+				if !binaryEqual(t, gotMover.Path.(*pathing.PathAStar), wantComponent.Path.(*pathing.PathAStar)) {
+					t.Errorf("entity %d: mover paths differ", entity)
+				}
+			default:
+				if !reflect.DeepEqual(gotComponent, wantComponent) {
+					t.Errorf("entity %d: got %+v, want %+v", entity, gotComponent, wantComponent)
+				}
+			}
+		}
+	}
+}
+
+func TestWorldBinary(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(world *World)
+	}{
+		{"empty", func(world *World) {}},
+		{"entities without components", func(world *World) {
+			world.NewEntity()
+			world.NewEntity()
+		}},
+		{"destroyed entities", func(world *World) {
+			world.NewEntity(&Position{1, 2})
+			world.Destroy(world.NewEntity(&Position{3, 4}))
+			world.NewEntity(&Position{5, 6})
+			world.Destroy(world.NewEntity())
+		}},
+		{"built-in components", func(world *World) {
+			fovMap := fov.NewMapBBQ(6, 5)
+			fovMap.SetBlocksLight(3, 2, true)
+			fovMap.Recompute(1, 1, 4, fov.Light{Lumens: 2})
+			path := pathing.NewPathAStarFromFunc(6, 5, func(x, y int) uint32 { return uint32(x) })
+			mover := &Mover{Path: path}
+			mover.MoveTo(4, 3)
+			mover.steps = path.Compute(1, 1, 4, 3)
+			world.NewEntity(
+				&Position{1, 1},
+				&Renderable{Rune: '@', Style: goro.Style{Foreground: goro.ColorWhite, Bold: true}, Z: 2},
+				&Viewer{Map: fovMap, Radius: 4, Light: fov.Light{Lumens: 2}, Cone: fov.Cone{Facing: 1.5, Arc: 2}},
+				&Memory{Remembered: map[Position]Renderable{{2, 2}: {Rune: '#'}, {3, 1}: {Rune: 'g', Z: 1}}},
+				mover,
+				&BlocksMovement{},
+			)
+			world.NewEntity(&Position{3, 1}, &Renderable{Rune: 'g', Z: 1}, &BlocksMovement{})
+		}},
+		{"registered components", func(world *World) {
+			world.NewEntity(&health{7, 10})
+			world.NewEntity(&Position{}, &health{})
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			world := NewWorld()
+			test.build(world)
+			data, err := world.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded := NewWorld()
+			decoded.NewEntity(&Position{9, 9})
+			decoded.AddSystem(SystemFunc(func(*World) {}), 0)
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			compareWorlds(t, decoded, world)
+			if len(decoded.systems) != 1 {
+				t.Errorf("got %d systems, want the 1 added before decoding", len(decoded.systems))
+			}
+			if got, want := decoded.NewEntity(), world.NewEntity(); got != want {
+				t.Errorf("got new entity %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWorldBinaryErrors(t *testing.T) {
+	world := NewWorld()
+	world.NewEntity(&unregistered{1})
+	if _, err := world.MarshalBinary(); err == nil {
+		t.Error("encoding an unregistered component did not fail")
+	}
+
+	valid, err := func() ([]byte, error) {
+		world := NewWorld()
+		world.NewEntity(&Position{1, 2})
+		return world.MarshalBinary()
+	}()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", valid[:len(valid)/2]},
+		{"garbage", []byte("not a world")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			world := NewWorld()
+			if err := world.UnmarshalBinary(test.data); err == nil {
+				t.Error("decoding did not fail")
+			}
+		})
+	}
+}
@@ -18,6 +18,10 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package goro
 
+import (
+	"time"
+)
+
 // Event is the Event interface.
 type Event interface {
 }
@@ -53,3 +57,10 @@ type EventMouse struct {
 // EventQuit represents a quit event.
 type EventQuit struct {
 }
+
+// EventTick represents a fixed timestep tick, sent at the rate given to Screen.SetTickRate.
+type EventTick struct {
+	Delta time.Duration // The time since the previous tick, which is a multiple of the fixed step if ticks were merged because the Screen fell behind.
+	Frame uint64        // The number of ticks sent so far, starting at 1. Merged ticks skip frames.
+	Time  time.Time     // The time the tick was sent.
+}
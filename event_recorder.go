@@ -0,0 +1,234 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventLogVersion is the version of the event log format written by EventLogWriter.
+const EventLogVersion = 1
+
+// Errors returned when reading event logs.
+var (
+	ErrEventLogHeader = errors.New("invalid event log header")
+	ErrEventLogType   = errors.New("unknown event type in event log")
+)
+
+// EventRecorder is an interface for receiving each Event delivered by a Screen's WaitEvent, PollEvent, WaitEventTimeout, or WaitEventContext.
+type EventRecorder interface {
+	RecordEvent(event Event)
+}
+
+// AddEventRecorder adds an EventRecorder to the Screen.
+func (screen *Screen) AddEventRecorder(recorder EventRecorder) {
+	screen.eventRecordersMutex.Lock()
+	defer screen.eventRecordersMutex.Unlock()
+	screen.eventRecorders = append(screen.eventRecorders, recorder)
+}
+
+// RemoveEventRecorder removes a previously added EventRecorder from the Screen.
+func (screen *Screen) RemoveEventRecorder(recorder EventRecorder) {
+	screen.eventRecordersMutex.Lock()
+	defer screen.eventRecordersMutex.Unlock()
+	for i, r := range screen.eventRecorders {
+		if r == recorder {
+			screen.eventRecorders = append(screen.eventRecorders[:i], screen.eventRecorders[i+1:]...)
+			return
+		}
+	}
+}
+
+// recordEvent sends the event to each of the Screen's EventRecorders.
+func (screen *Screen) recordEvent(event Event) {
+	screen.eventRecordersMutex.Lock()
+	defer screen.eventRecordersMutex.Unlock()
+	for _, recorder := range screen.eventRecorders {
+		recorder.RecordEvent(event)
+	}
+}
+
+// EventLog is a recording of events that can be replayed with BackendReplay.
+type EventLog struct {
+	Seed   int64
+	Events []LoggedEvent
+}
+
+// LoggedEvent is an Event and when it was delivered, relative to the start of the recording.
+type LoggedEvent struct {
+	Time  time.Duration
+	Event Event
+}
+
+// eventLogHeader is the first line of an event log.
+type eventLogHeader struct {
+	Version int   `json:"version"`
+	Seed    int64 `json:"seed"`
+}
+
+// eventLogLine is each following line of an event log.
+type eventLogLine struct {
+	Time  float64         `json:"t"`
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event,omitempty"`
+}
+
+// EventLogWriter is an EventRecorder that writes an event log. The log is JSON lines: a header containing the version and random seed, followed by a line for each event with its time in seconds, its type, and its fields.
+type EventLogWriter struct {
+	w       io.Writer
+	start   time.Time
+	started bool
+	err     error
+	mutex   sync.Mutex
+}
+
+// NewEventLogWriter returns an EventLogWriter that writes to w, recording seed in the header. For replays to be exact the game should call SetSeed with the same seed.
+func NewEventLogWriter(w io.Writer, seed int64) (*EventLogWriter, error) {
+	header, err := json.Marshal(eventLogHeader{Version: EventLogVersion, Seed: seed})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(header, '\n')); err != nil {
+		return nil, err
+	}
+	return &EventLogWriter{w: w}, nil
+}
+
+// RecordEvent writes the event, timestamped relative to the first recorded event.
+func (writer *EventLogWriter) RecordEvent(event Event) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.err != nil {
+		return
+	}
+	now := time.Now()
+	if !writer.started {
+		writer.started = true
+		writer.start = now
+	}
+	line, err := marshalLoggedEvent(LoggedEvent{Time: now.Sub(writer.start), Event: event})
+	if err != nil {
+		writer.err = err
+		return
+	}
+	if _, err := writer.w.Write(append(line, '\n')); err != nil {
+		writer.err = err
+	}
+}
+
+// Err returns the first error encountered while writing.
+func (writer *EventLogWriter) Err() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return writer.err
+}
+
+// ReadEventLog reads an event log written by EventLogWriter.
+func ReadEventLog(r io.Reader) (*EventLog, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrEventLogHeader
+	}
+	var header eventLogHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version != EventLogVersion {
+		return nil, ErrEventLogHeader
+	}
+	log := &EventLog{Seed: header.Seed}
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		event, err := unmarshalLoggedEvent(scanner.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		log.Events = append(log.Events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// marshalLoggedEvent encodes a single event log line.
+func marshalLoggedEvent(logged LoggedEvent) ([]byte, error) {
+	line := eventLogLine{Time: logged.Time.Seconds()}
+	switch logged.Event.(type) {
+	case EventKey:
+		line.Type = "key"
+	case EventMouse:
+		line.Type = "mouse"
+	case EventResize:
+		line.Type = "resize"
+	case EventTick:
+		line.Type = "tick"
+	case EventQuit:
+		line.Type = "quit"
+	default:
+		return nil, ErrEventLogType
+	}
+	event, err := json.Marshal(logged.Event)
+	if err != nil {
+		return nil, err
+	}
+	line.Event = event
+	return json.Marshal(line)
+}
+
+// unmarshalLoggedEvent decodes a single event log line.
+func unmarshalLoggedEvent(data []byte) (LoggedEvent, error) {
+	var line eventLogLine
+	if err := json.Unmarshal(data, &line); err != nil {
+		return LoggedEvent{}, err
+	}
+	logged := LoggedEvent{Time: time.Duration(line.Time * float64(time.Second))}
+	var err error
+	switch line.Type {
+	case "key":
+		var event EventKey
+		err = json.Unmarshal(line.Event, &event)
+		logged.Event = event
+	case "mouse":
+		var event EventMouse
+		err = json.Unmarshal(line.Event, &event)
+		logged.Event = event
+	case "resize":
+		var event EventResize
+		err = json.Unmarshal(line.Event, &event)
+		logged.Event = event
+	case "tick":
+		var event EventTick
+		err = json.Unmarshal(line.Event, &event)
+		logged.Event = event
+	case "quit":
+		logged.Event = EventQuit{}
+	default:
+		err = ErrEventLogType
+	}
+	return logged, err
+}
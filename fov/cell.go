@@ -21,7 +21,9 @@ package fov
 // Cell is the state for a given position in an FoVMap.
 type Cell struct {
 	Lighting       Light
-	Visible        bool // Whether this cell is within the FoV.
+	Visible        bool   // Whether this cell is within the FoV.
+	Explored       bool   // Whether this cell has ever been within the FoV.
+	LastSeen       uint32 // The map's Turn when this cell was last within the FoV.
 	BlocksLight    bool
 	BlocksMovement bool
 }
@@ -0,0 +1,143 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fov
+
+import (
+	"math"
+)
+
+// Cone limits a field of view to an arc around a facing. Facing is in radians, with 0 facing toward positive x and angles increasing toward positive y. An Arc of 0, or of 2π or more, sees in every direction.
+type Cone struct {
+	Facing float64
+	Arc    float64
+}
+
+// ConeMap is a Map that can limit its FoV to a Cone. Maps that do not implement it can be used with RecomputeCone.
+type ConeMap interface {
+	Map
+	ComputeCone(cX, cY int, radius int, light Light, cone Cone)
+	RecomputeCone(cX, cY int, radius int, light Light, cone Cone)
+}
+
+// RecomputeCone recomputes fovMap's FoV limited to cone. Maps that are not a ConeMap are recomputed in full, then have the cells outside of the cone hidden. If such a map is a MemoryMap, the memory of the hidden cells is restored so that they are not explored by the full recompute.
+func RecomputeCone(fovMap Map, cX, cY int, radius int, light Light, cone Cone) {
+	if coneMap, ok := fovMap.(ConeMap); ok {
+		coneMap.RecomputeCone(cX, cY, radius, light, cone)
+		return
+	}
+	type memory struct {
+		x, y     int
+		explored bool
+		lastSeen uint32
+	}
+	var memories []memory
+	memoryMap, remembers := fovMap.(MemoryMap)
+	if remembers {
+		for y := cY - radius; y <= cY+radius; y++ {
+			for x := cX - radius; x <= cX+radius; x++ {
+				if cone.Contains(x-cX, y-cY) || memoryMap.CheckBounds(x, y) != nil {
+					continue
+				}
+				lastSeen, explored := memoryMap.LastSeen(x, y)
+				memories = append(memories, memory{x, y, explored, lastSeen})
+			}
+		}
+	}
+	fovMap.Recompute(cX, cY, radius, light)
+	for y := cY - radius; y <= cY+radius; y++ {
+		for x := cX - radius; x <= cX+radius; x++ {
+			if !cone.Contains(x-cX, y-cY) {
+				fovMap.SetVisible(x, y, false)
+			}
+		}
+	}
+	for _, m := range memories {
+		memoryMap.SetExplored(m.x, m.y, m.explored)
+		memoryMap.SetLastSeen(m.x, m.y, m.lastSeen)
+	}
+}
+
+// FacingToward returns the Facing that looks from the origin toward the cell offset by dx and dy.
+func FacingToward(dx, dy int) float64 {
+	return math.Atan2(float64(dy), float64(dx))
+}
+
+// Contains returns whether the cell offset by dx and dy from the cone's origin is within its arc. The origin itself is always contained.
+func (cone Cone) Contains(dx, dy int) bool {
+	if cone.Arc <= 0 || cone.Arc >= 2*math.Pi || (dx == 0 && dy == 0) {
+		return true
+	}
+	delta := math.Remainder(math.Atan2(float64(dy), float64(dx))-cone.Facing, 2*math.Pi)
+	return math.Abs(delta) <= cone.Arc/2
+}
+
+// LineOfSight returns whether the cell at x1,y1 can be seen from x0,y0 on fovMap, using the same lines as Compute. The target cell itself may block light.
+func LineOfSight(fovMap Map, x0, y0, x1, y1 int) bool {
+	return lineOfSight(fovMap, x0, y0, x1, y1)
+}
+
+// lightBlocker is the part of a Map used to trace lines of sight.
+type lightBlocker interface {
+	CheckBounds(x, y int) error
+	BlocksLight(x, y int) bool
+}
+
+// lineOfSight steps from x0,y0 toward x1,y1, returning false if any cell before x1,y1 blocks light or is out of bounds, or if x1,y1 is out of bounds.
+func lineOfSight(fovMap lightBlocker, x0, y0, x1, y1 int) bool {
+	var quadrantX, quadrantY int
+
+	destX := x1 - x0
+	destY := y1 - y0
+
+	if x0 < x1 {
+		quadrantX = 1
+	} else {
+		quadrantX = -1
+	}
+
+	if y0 < y1 {
+		quadrantY = 1
+	} else {
+		quadrantY = -1
+	}
+
+	nextX := x0
+	nextY := y0
+
+	distance := math.Sqrt(float64(destX*destX + destY*destY))
+
+	for nextX != x1 || nextY != y1 {
+		if fovMap.CheckBounds(nextX, nextY) != nil {
+			return false
+		}
+		if fovMap.BlocksLight(nextX, nextY) {
+			return false
+		}
+
+		if (math.Abs(float64(destY*(nextX-x0+quadrantX)-destX*(nextY-y0))) / distance) < 0.5 {
+			nextX += quadrantX
+		} else if (math.Abs(float64(destY*(nextX-x0)-destX*(nextY-y0+quadrantY))) / distance) < 0.5 {
+			nextY += quadrantY
+		} else {
+			nextX += quadrantX
+			nextY += quadrantY
+		}
+	}
+	return fovMap.CheckBounds(x1, y1) == nil
+}
@@ -0,0 +1,174 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fov
+
+import (
+	"sort"
+)
+
+// Viewer is a point of view computed by Layers.
+type Viewer struct {
+	X, Y   int
+	Radius int
+	Cone   Cone
+}
+
+// Contains returns whether the cell at x and y is within the viewer's radius and cone, ignoring anything that blocks light.
+func (viewer Viewer) Contains(x, y int) bool {
+	dx, dy := x-viewer.X, y-viewer.Y
+	return dx*dx+dy*dy < viewer.Radius*viewer.Radius && viewer.Cone.Contains(dx, dy)
+}
+
+// layer is the visibility computed for a single viewer.
+type layer struct {
+	viewer  Viewer
+	width   int
+	height  int
+	visible []bool
+}
+
+// Layers computes separate fields of view for multiple viewers over a shared Map, such as guards in a stealth game, without changing the Map's own visibility.
+type Layers struct {
+	terrain Map
+	layers  map[int]*layer
+}
+
+// NewLayers returns Layers that trace lines of sight over terrain.
+func NewLayers(terrain Map) *Layers {
+	return &Layers{
+		terrain: terrain,
+		layers:  make(map[int]*layer),
+	}
+}
+
+// Compute sets the viewer with the given id and computes its field of view.
+func (layers *Layers) Compute(id int, viewer Viewer) {
+	l, ok := layers.layers[id]
+	if !ok {
+		l = &layer{}
+		layers.layers[id] = l
+	}
+	l.viewer = viewer
+	layers.compute(l)
+}
+
+// Recompute recomputes every viewer's field of view, such as after the terrain has changed.
+func (layers *Layers) Recompute() {
+	for _, l := range layers.layers {
+		layers.compute(l)
+	}
+}
+
+// compute fills l's visibility from its viewer.
+func (layers *Layers) compute(l *layer) {
+	l.width, l.height = layers.terrain.Width(), layers.terrain.Height()
+	if cap(l.visible) < l.width*l.height {
+		l.visible = make([]bool, l.width*l.height)
+	} else {
+		l.visible = l.visible[:l.width*l.height]
+		for i := range l.visible {
+			l.visible[i] = false
+		}
+	}
+	viewer := l.viewer
+	for y := viewer.Y - viewer.Radius; y <= viewer.Y+viewer.Radius; y++ {
+		for x := viewer.X - viewer.Radius; x <= viewer.X+viewer.Radius; x++ {
+			if x < 0 || y < 0 || x >= l.width || y >= l.height || !viewer.Contains(x, y) {
+				continue
+			}
+			if lineOfSight(layers.terrain, viewer.X, viewer.Y, x, y) {
+				l.visible[y*l.width+x] = true
+			}
+		}
+	}
+}
+
+// Remove removes the viewer with the given id.
+func (layers *Layers) Remove(id int) {
+	delete(layers.layers, id)
+}
+
+// Viewer returns the viewer with the given id, and false if there is none.
+func (layers *Layers) Viewer(id int) (Viewer, bool) {
+	l, ok := layers.layers[id]
+	if !ok {
+		return Viewer{}, false
+	}
+	return l.viewer, true
+}
+
+// IDs returns the ids of all viewers in ascending order.
+func (layers *Layers) IDs() []int {
+	ids := make([]int, 0, len(layers.layers))
+	for id := range layers.layers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Visible returns whether the cell at x and y was visible to the viewer with the given id when its field of view was last computed.
+func (layers *Layers) Visible(id int, x, y int) bool {
+	l, ok := layers.layers[id]
+	if !ok || x < 0 || y < 0 || x >= l.width || y >= l.height {
+		return false
+	}
+	return l.visible[y*l.width+x]
+}
+
+// VisibleToAny returns whether the cell at x and y is visible to at least one viewer.
+func (layers *Layers) VisibleToAny(x, y int) bool {
+	for id := range layers.layers {
+		if layers.Visible(id, x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleTo returns the ids of the viewers that can see the cell at x and y, in ascending order.
+func (layers *Layers) VisibleTo(x, y int) []int {
+	var ids []int
+	for _, id := range layers.IDs() {
+		if layers.Visible(id, x, y) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// CanSee returns whether the viewer with the given id can currently see the cell at x and y, tracing a single line of sight over the terrain rather than recomputing its whole field of view.
+func (layers *Layers) CanSee(id int, x, y int) bool {
+	l, ok := layers.layers[id]
+	if !ok || !l.viewer.Contains(x, y) {
+		return false
+	}
+	return lineOfSight(layers.terrain, l.viewer.X, l.viewer.Y, x, y)
+}
+
+// Aggregate marks every cell visible to at least one viewer as visible on fovMap, which should be the same size as the terrain.
+func (layers *Layers) Aggregate(fovMap Map) {
+	for _, l := range layers.layers {
+		for i, visible := range l.visible {
+			if visible {
+				fovMap.SetVisible(i%l.width, i/l.width, true)
+			}
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 type MapBase struct {
 	width, height int
 	cells         [][]Cell
+	turn          uint32
 }
 
 // Resize resizes the given MapBase to the provided size.
@@ -118,6 +119,9 @@ func (fovMap *MapBase) SetVisible(x, y int, visible bool) error {
 		return err
 	}
 	fovMap.cells[y][x].Visible = visible
+	if visible {
+		fovMap.see(x, y)
+	}
 	return nil
 }
 
@@ -149,8 +153,9 @@ func (fovMap *MapBase) CheckBounds(x, y int) error {
 	return nil
 }
 
-// Reset resets the visibility state of all cells.
+// Reset resets the visibility state of all cells and advances the map's Turn. Explored cells remain explored.
 func (fovMap *MapBase) Reset() {
+	fovMap.turn++
 	for y := range fovMap.cells {
 		for x := range fovMap.cells[y] {
 			fovMap.cells[y][x].Visible = false
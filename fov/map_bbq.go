@@ -36,62 +36,31 @@ func NewMapBBQ(width, height int) (fovMap *MapBBQ) {
 
 // Compute calculates the FOV for our BBQ.
 func (fovMap *MapBBQ) Compute(cX, cY int, radius int, light Light) {
+	fovMap.ComputeCone(cX, cY, radius, light, Cone{})
+}
+
+// ComputeCone calculates the FOV for our BBQ, limited to cells within cone.
+func (fovMap *MapBBQ) ComputeCone(cX, cY int, radius int, light Light, cone Cone) {
 	maxRadius := math.Sqrt(float64(radius*radius + radius*radius))
 	for i := -radius; i <= radius; i++ {
 		for j := -radius; j <= radius; j++ {
-			if i*i+j*j < radius*radius {
+			if i*i+j*j < radius*radius && cone.Contains(i, j) {
 				fovMap.computeLOS(cX, cY, cX+i, cY+j, maxRadius, light)
 			}
 		}
 	}
 }
 
-// computeLOS checks the line of sight between x0,y0 to x1,y1, setting the cell at x1,y1 to
+// computeLOS checks the line of sight between x0,y0 to x1,y1, setting the cell at x1,y1 to visible and lighting it if it can be seen.
 func (fovMap *MapBBQ) computeLOS(x0, y0, x1, y1 int, maxRadius float64, light Light) {
-	var quadrantX, quadrantY, nextX, nextY, destX, destY int
-	var distance float64
-
-	destX = x1 - x0
-	destY = y1 - y0
-
-	if x0 < x1 {
-		quadrantX = 1
-	} else {
-		quadrantX = -1
-	}
-
-	if y0 < y1 {
-		quadrantY = 1
-	} else {
-		quadrantY = -1
-	}
-
-	nextX = x0
-	nextY = y0
-
-	distance = math.Sqrt(float64(destX*destX + destY*destY))
-
-	for nextX != x1 || nextY != y1 {
-		if fovMap.CheckBounds(nextX, nextY) != nil {
-			return
-		}
-		if fovMap.BlocksLight(nextX, nextY) {
-			return
-		}
-
-		if (math.Abs(float64(destY*(nextX-x0+quadrantX)-destX*(nextY-y0))) / distance) < 0.5 {
-			nextX += quadrantX
-		} else if (math.Abs(float64(destY*(nextX-x0)-destX*(nextY-y0+quadrantY))) / distance) < 0.5 {
-			nextY += quadrantY
-		} else {
-			nextX += quadrantX
-			nextY += quadrantY
-		}
-	}
-	if fovMap.CheckBounds(x1, y1) != nil {
+	if !lineOfSight(&fovMap.MapBase, x0, y0, x1, y1) {
 		return
 	}
+	destX := x1 - x0
+	destY := y1 - y0
+	distance := math.Sqrt(float64(destX*destX + destY*destY))
 	fovMap.cells[y1][x1].Visible = true
+	fovMap.see(x1, y1)
 	// Do light calculations (?)
 	// It might be best if we make a separate light-only FoV calculation that aggregates multiple FoVs (from each light) and then concatenates their values together in a final FoV. But, for now, this works for a player-only system.
 	lumens := light.Lumens * int16(distance/maxRadius)
@@ -108,3 +77,9 @@ func (fovMap *MapBBQ) Recompute(cX, cY int, radius int, light Light) {
 	fovMap.Reset()
 	fovMap.Compute(cX, cY, radius, light)
 }
+
+// RecomputeCone calls Reset() then ComputeCone()
+func (fovMap *MapBBQ) RecomputeCone(cX, cY int, radius int, light Light, cone Cone) {
+	fovMap.Reset()
+	fovMap.ComputeCone(cX, cY, radius, light, cone)
+}
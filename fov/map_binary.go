@@ -0,0 +1,112 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fov
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// mapBinaryVersion is the version of the encoding written by MapBase.MarshalBinary.
+const mapBinaryVersion = 2
+
+// ErrMapBinary is returned when decoding invalid map data.
+var ErrMapBinary = errors.New("invalid map data")
+
+// Flags used to encode a Cell's booleans.
+const (
+	cellFlagVisible = 1 << iota
+	cellFlagBlocksLight
+	cellFlagBlocksMovement
+	cellFlagExplored
+)
+
+// MarshalBinary encodes the map's size, turn, and cells. The encoding starts with a version byte, followed by the width, height, and turn as little-endian uint32s, then each cell's lumens as an int16, its flags as a byte, and its last seen turn as a uint32, row by row.
+func (fovMap *MapBase) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(mapBinaryVersion)
+	binary.Write(&buf, binary.LittleEndian, uint32(fovMap.width))
+	binary.Write(&buf, binary.LittleEndian, uint32(fovMap.height))
+	binary.Write(&buf, binary.LittleEndian, fovMap.turn)
+	for y := range fovMap.cells {
+		for _, cell := range fovMap.cells[y] {
+			var flags uint8
+			if cell.Visible {
+				flags |= cellFlagVisible
+			}
+			if cell.BlocksLight {
+				flags |= cellFlagBlocksLight
+			}
+			if cell.BlocksMovement {
+				flags |= cellFlagBlocksMovement
+			}
+			if cell.Explored {
+				flags |= cellFlagExplored
+			}
+			binary.Write(&buf, binary.LittleEndian, cell.Lighting.Lumens)
+			buf.WriteByte(flags)
+			binary.Write(&buf, binary.LittleEndian, cell.LastSeen)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes data encoded by MarshalBinary, resizing the map to match. Data from version 1, which has no turn or memory, is also accepted.
+func (fovMap *MapBase) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 || (data[0] != 1 && data[0] != mapBinaryVersion) {
+		return ErrMapBinary
+	}
+	version := data[0]
+	width := int(binary.LittleEndian.Uint32(data[1:]))
+	height := int(binary.LittleEndian.Uint32(data[5:]))
+	data = data[9:]
+	var turn uint32
+	cellSize := 3
+	if version >= 2 {
+		if len(data) < 4 {
+			return ErrMapBinary
+		}
+		turn = binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		cellSize = 7
+	}
+	if len(data) != width*height*cellSize {
+		return ErrMapBinary
+	}
+	fovMap.Resize(width, height)
+	fovMap.turn = turn
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			flags := data[2]
+			fovMap.cells[y][x] = Cell{
+				Lighting:       Light{Lumens: int16(binary.LittleEndian.Uint16(data))},
+				Visible:        flags&cellFlagVisible != 0,
+				BlocksLight:    flags&cellFlagBlocksLight != 0,
+				BlocksMovement: flags&cellFlagBlocksMovement != 0,
+				Explored:       flags&cellFlagExplored != 0,
+			}
+			if version >= 2 {
+				fovMap.cells[y][x].LastSeen = binary.LittleEndian.Uint32(data[3:])
+			}
+			data = data[cellSize:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fov
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// newTestMap returns a map of the given size whose cells each differ.
+func newTestMap(width, height int, turn uint32) *MapBase {
+	fovMap := &MapBase{}
+	fovMap.Resize(width, height)
+	fovMap.turn = turn
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			fovMap.SetCell(x, y, Cell{
+				Lighting:       Light{Lumens: int16(i*37 - 100)},
+				Visible:        i%2 == 0,
+				Explored:       i%3 == 0,
+				LastSeen:       uint32(i * 1000),
+				BlocksLight:    i%5 == 0,
+				BlocksMovement: i%7 == 0,
+			})
+		}
+	}
+	return fovMap
+}
+
+// mapV1 encodes cells in the version 1 format, which has no turn or last seen turns.
+func mapV1(width, height int, cells ...Cell) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(1)
+	binary.Write(&buf, binary.LittleEndian, uint32(width))
+	binary.Write(&buf, binary.LittleEndian, uint32(height))
+	for _, cell := range cells {
+		var flags uint8
+		if cell.Visible {
+			flags |= cellFlagVisible
+		}
+		if cell.BlocksLight {
+			flags |= cellFlagBlocksLight
+		}
+		if cell.BlocksMovement {
+			flags |= cellFlagBlocksMovement
+		}
+		if cell.Explored {
+			flags |= cellFlagExplored
+		}
+		binary.Write(&buf, binary.LittleEndian, cell.Lighting.Lumens)
+		buf.WriteByte(flags)
+	}
+	return buf.Bytes()
+}
+
+func TestMapBinary(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		turn          uint32
+	}{
+		{"empty", 0, 0, 0},
+		{"single cell", 1, 1, 0},
+		{"wide", 9, 2, 5},
+		{"tall", 3, 8, 1 << 31},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := newTestMap(test.width, test.height, test.turn)
+			data, err := original.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Decoding into a differently sized map checks that it is resized.
+			decoded := newTestMap(4, 4, 99)
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Width() != test.width || decoded.Height() != test.height || decoded.Turn() != test.turn {
+				t.Errorf("got %dx%d turn %d, want %dx%d turn %d", decoded.Width(), decoded.Height(), decoded.Turn(), test.width, test.height, test.turn)
+			}
+			for y := 0; y < test.height; y++ {
+				if !reflect.DeepEqual(decoded.cells[y], original.cells[y]) {
+					t.Errorf("row %d: got %v, want %v", y, decoded.cells[y], original.cells[y])
+				}
+			}
+		})
+	}
+}
+
+func TestMapBinaryV1(t *testing.T) {
+	cells := []Cell{
+		{Lighting: Light{Lumens: 12}, Visible: true},
+		{Lighting: Light{Lumens: -4}, Explored: true, BlocksLight: true},
+		{BlocksMovement: true},
+		{},
+		{Explored: true, Visible: true, BlocksLight: true, BlocksMovement: true},
+		{Lighting: Light{Lumens: 32767}},
+	}
+	decoded := newTestMap(2, 2, 8)
+	if err := decoded.UnmarshalBinary(mapV1(3, 2, cells...)); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Width() != 3 || decoded.Height() != 2 || decoded.Turn() != 0 {
+		t.Fatalf("got %dx%d turn %d, want 3x2 turn 0", decoded.Width(), decoded.Height(), decoded.Turn())
+	}
+	for i, want := range cells {
+		if got := decoded.cells[i/3][i%3]; got != want {
+			t.Errorf("cell %d: got %+v, want %+v", i, got, want)
+		}
+	}
+
+	// Upgrading writes the current version, which decodes to the same cells.
+	data, _ := decoded.MarshalBinary()
+	if data[0] != mapBinaryVersion {
+		t.Errorf("got version %d, want %d", data[0], mapBinaryVersion)
+	}
+	upgraded := &MapBase{}
+	if err := upgraded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(upgraded.cells, decoded.cells) {
+		t.Errorf("got %v, want %v", upgraded.cells, decoded.cells)
+	}
+}
+
+func TestMapBinaryErrors(t *testing.T) {
+	valid, _ := newTestMap(3, 2, 4).MarshalBinary()
+	version := append([]byte(nil), valid...)
+	version[0] = mapBinaryVersion + 1
+	size := append([]byte(nil), valid...)
+	size[1]++
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:8]},
+		{"version 0", append([]byte{0}, valid[1:]...)},
+		{"newer version", version},
+		{"no turn", valid[:11]},
+		{"truncated cells", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+		{"wrong size", size},
+		{"truncated version 1", mapV1(2, 1, Cell{})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fovMap := newTestMap(1, 1, 3)
+			if err := fovMap.UnmarshalBinary(test.data); err != ErrMapBinary {
+				t.Fatalf("got %v, want ErrMapBinary", err)
+			}
+			if fovMap.Width() != 1 || fovMap.Height() != 1 || fovMap.Turn() != 3 {
+				t.Errorf("a failed decode changed the map to %dx%d turn %d", fovMap.Width(), fovMap.Height(), fovMap.Turn())
+			}
+		})
+	}
+}
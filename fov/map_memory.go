@@ -0,0 +1,119 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fov
+
+// MemoryMap is a Map that remembers which cells have been within its FoV. Maps embedding MapBase implement it.
+type MemoryMap interface {
+	Map
+	Explored(x, y int) bool
+	SetExplored(x, y int, explored bool) error
+	Remembered(x, y int) bool
+	LastSeen(x, y int) (uint32, bool)
+	SetLastSeen(x, y int, turn uint32) error
+	Turn() uint32
+	EachRemembered(fn func(x, y int))
+	Forget()
+	ForgetCell(x, y int) error
+}
+
+// see marks the cell at x and y as explored and seen during the current turn. The cell must be within bounds.
+func (fovMap *MapBase) see(x, y int) {
+	fovMap.cells[y][x].Explored = true
+	fovMap.cells[y][x].LastSeen = fovMap.turn
+}
+
+// Turn returns how many times the map has been Reset, which Recompute does for each new FoV.
+func (fovMap *MapBase) Turn() uint32 {
+	return fovMap.turn
+}
+
+// Explored returns whether a given cell has ever been within the FoV. Returns false if x or y is out of bounds.
+func (fovMap *MapBase) Explored(x, y int) bool {
+	if err := fovMap.CheckBounds(x, y); err != nil {
+		return false
+	}
+	return fovMap.cells[y][x].Explored
+}
+
+// SetExplored sets whether a given cell has been within the FoV, such as when revealed by a magic map.
+func (fovMap *MapBase) SetExplored(x, y int, explored bool) error {
+	if err := fovMap.CheckBounds(x, y); err != nil {
+		return err
+	}
+	fovMap.cells[y][x].Explored = explored
+	return nil
+}
+
+// Remembered returns whether a given cell has been explored but is not currently within the FoV, as is drawn with fog of war. Returns false if x or y is out of bounds.
+func (fovMap *MapBase) Remembered(x, y int) bool {
+	if err := fovMap.CheckBounds(x, y); err != nil {
+		return false
+	}
+	return fovMap.cells[y][x].Explored && !fovMap.cells[y][x].Visible
+}
+
+// LastSeen returns the map's Turn when a given cell was last within the FoV, and false if it has never been.
+func (fovMap *MapBase) LastSeen(x, y int) (uint32, bool) {
+	if err := fovMap.CheckBounds(x, y); err != nil {
+		return 0, false
+	}
+	return fovMap.cells[y][x].LastSeen, fovMap.cells[y][x].Explored
+}
+
+// SetLastSeen sets the map's Turn when a given cell was last within the FoV.
+func (fovMap *MapBase) SetLastSeen(x, y int, turn uint32) error {
+	if err := fovMap.CheckBounds(x, y); err != nil {
+		return err
+	}
+	fovMap.cells[y][x].LastSeen = turn
+	return nil
+}
+
+// EachRemembered calls fn with the position of each cell that has been explored but is not currently within the FoV, row by row.
+func (fovMap *MapBase) EachRemembered(fn func(x, y int)) {
+	for y := range fovMap.cells {
+		for x := range fovMap.cells[y] {
+			if fovMap.cells[y][x].Explored && !fovMap.cells[y][x].Visible {
+				fn(x, y)
+			}
+		}
+	}
+}
+
+// Forget clears the exploration of every cell that is not currently within the FoV.
+func (fovMap *MapBase) Forget() {
+	for y := range fovMap.cells {
+		for x := range fovMap.cells[y] {
+			if !fovMap.cells[y][x].Visible {
+				fovMap.cells[y][x].Explored = false
+				fovMap.cells[y][x].LastSeen = 0
+			}
+		}
+	}
+}
+
+// ForgetCell clears the exploration of a given cell.
+func (fovMap *MapBase) ForgetCell(x, y int) error {
+	if err := fovMap.CheckBounds(x, y); err != nil {
+		return err
+	}
+	fovMap.cells[y][x].Explored = false
+	fovMap.cells[y][x].LastSeen = 0
+	return nil
+}
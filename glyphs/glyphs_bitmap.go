@@ -0,0 +1,128 @@
+package glyphs
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	_ "image/png" // Bitmap glyph sheets are usually PNGs.
+	"os"
+)
+
+// ErrCellSize is returned when a Bitmap's glyph width or height is not positive.
+var ErrCellSize = errors.New("bitmap cell size must be positive")
+
+// Bitmap is our bitmap glyph sheet data. The sheet is a grid of equally sized glyphs, which by default are indexed in Code Page 437 order.
+type Bitmap struct {
+	mask                  *image.Alpha
+	cellWidth, cellHeight int
+	columns               int
+	size                  float64
+	width, height         int
+	runes                 map[rune]int
+}
+
+// LoadBitmap loads a Bitmap from the image at the provided path, with each glyph being cellWidth by cellHeight pixels.
+func LoadBitmap(path string, cellWidth, cellHeight int) (Glyphs, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	bitmap, err := LoadBitmapFromImage(img, cellWidth, cellHeight)
+	if err != nil {
+		return nil, err
+	}
+	return bitmap, nil
+}
+
+// LoadBitmapFromImage creates a Bitmap from the provided glyph sheet image. Glyph coverage is taken from each pixel's alpha multiplied by its brightness, so both light-on-transparent and light-on-black sheets work.
+func LoadBitmapFromImage(img image.Image, cellWidth, cellHeight int) (*Bitmap, error) {
+	if cellWidth <= 0 || cellHeight <= 0 {
+		return nil, ErrCellSize
+	}
+	bounds := img.Bounds()
+	mask := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			// Colors from RGBA are alpha-premultiplied, so their luminance already accounts for alpha.
+			lum := (299*r + 587*g + 114*b) / 1000
+			mask.SetAlpha(x-bounds.Min.X, y-bounds.Min.Y, color.Alpha{A: uint8(lum >> 8)})
+		}
+	}
+	f := &Bitmap{
+		mask:       mask,
+		cellWidth:  cellWidth,
+		cellHeight: cellHeight,
+		columns:    bounds.Dx() / cellWidth,
+		width:      cellWidth,
+		height:     cellHeight,
+		size:       float64(cellHeight),
+		runes:      make(map[rune]int),
+	}
+	for i, r := range CP437 {
+		if _, ok := f.runes[r]; !ok {
+			f.runes[r] = i
+		}
+	}
+	return f, nil
+}
+
+// Type returns BitmapType.
+func (f *Bitmap) Type() Type {
+	return BitmapType
+}
+
+// SetSize sets the glyph height in pixels, scaling the width to match.
+func (f *Bitmap) SetSize(size float64) {
+	if size <= 0 {
+		return
+	}
+	f.size = size
+	f.height = int(size)
+	f.width = int(size * float64(f.cellWidth) / float64(f.cellHeight))
+}
+
+// Width gets the width of a glyph.
+func (f *Bitmap) Width() int {
+	return f.width
+}
+
+// Height gets the height of a glyph.
+func (f *Bitmap) Height() int {
+	return f.height
+}
+
+// Ascent returns the height of a glyph, as bitmap glyphs have no baseline.
+func (f *Bitmap) Ascent() int {
+	return f.height
+}
+
+// SetRune maps the rune r to the glyph at index in the sheet, counting left to right and top to bottom.
+func (f *Bitmap) SetRune(r rune, index int) {
+	f.runes[r] = index
+}
+
+// Mask returns the coverage mask of the whole glyph sheet.
+func (f *Bitmap) Mask() *image.Alpha {
+	return f.mask
+}
+
+// Glyph returns the unscaled bounds of the rune's glyph within Mask and whether the rune has a glyph.
+func (f *Bitmap) Glyph(r rune) (image.Rectangle, bool) {
+	index, ok := f.runes[r]
+	if !ok || f.columns == 0 {
+		return image.Rectangle{}, false
+	}
+	x := (index % f.columns) * f.cellWidth
+	y := (index / f.columns) * f.cellHeight
+	rect := image.Rect(x, y, x+f.cellWidth, y+f.cellHeight)
+	if !rect.In(f.mask.Bounds()) {
+		return image.Rectangle{}, false
+	}
+	return rect, true
+}
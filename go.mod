@@ -3,6 +3,7 @@ module github.com/kettek/goro
 go 1.12
 
 require (
+	github.com/BurntSushi/toml v0.3.0
 	github.com/gdamore/tcell v1.1.2
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
 	github.com/hajimehoshi/ebiten v1.9.3
@@ -18,30 +18,46 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 package goro
 
-import (
-	"os"
-)
+// defaultApp is the App used by the package-level functions.
+var defaultApp *App
 
-var globalBackend Backend
-
-// Init initializes a given Backend interface. Built-in options are EbitenBackend and TCellBackend.
-func Init(backend Backend) error {
-	globalBackend = backend
-	return backend.Init()
+// Init initializes a given Backend interface for use with the package-level functions. Built-in options are EbitenBackend and TCellBackend. Use NewApp to run more than one Backend.
+func Init(backend Backend) (err error) {
+	defaultApp, err = NewApp(backend)
+	return err
 }
 
-// Quit tells the current Backend to Quit before calling os.Exit.
+// Quit stops the App created by Init, causing Run to return.
 func Quit() {
-	globalBackend.Quit()
-	os.Exit(0)
+	if defaultApp != nil {
+		defaultApp.Quit()
+	}
+}
+
+// QuitWithError stops the App created by Init, causing Run to return err.
+func QuitWithError(err error) {
+	if defaultApp != nil {
+		defaultApp.QuitWithError(err)
+	}
 }
 
 // Setup takes a callback for setting up goro before the backend creates any windows. Optional.
 func Setup(cb func(*Screen)) error {
-	return globalBackend.Setup(cb)
+	if defaultApp == nil {
+		return ErrAppNotInitialized
+	}
+	return defaultApp.Setup(cb)
 }
 
-// Run runs the Backend with the provided logic callback.
+// Run runs the Backend with the provided logic callback until Quit is called.
 func Run(cb func(*Screen)) error {
-	return globalBackend.Run(cb)
+	if defaultApp == nil {
+		return ErrAppNotInitialized
+	}
+	return defaultApp.Run(cb)
+}
+
+// DefaultApp returns the App created by Init, or nil if Init has not been called.
+func DefaultApp() *App {
+	return defaultApp
 }
@@ -0,0 +1,172 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// ErrPalette is returned when a palette file cannot be parsed.
+var ErrPalette = errors.New("invalid palette")
+
+// Palette is a list of colors.
+type Palette []Color
+
+// NearestIndex returns the index of the palette color perceptually closest to c, or -1 if the palette is empty.
+func (palette Palette) NearestIndex(c Color) int {
+	lab := toLab(c)
+	best, bestDistance := -1, 0.0
+	for i, p := range palette {
+		if d := labDistance(lab, toLab(p)); best < 0 || d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+// Nearest returns the palette color perceptually closest to c, or ColorNone if the palette is empty.
+func (palette Palette) Nearest(c Color) Color {
+	if i := palette.NearestIndex(c); i >= 0 {
+		return palette[i]
+	}
+	return ColorNone
+}
+
+// PaletteXterm256 is the xterm 256 color palette.
+var PaletteXterm256 = append(Palette(nil), xtermPalette[:]...)
+
+// PaletteDB32 is the DawnBringer 32 color palette.
+var PaletteDB32 = Palette{
+	{0x00, 0x00, 0x00, 0xFF}, {0x22, 0x20, 0x34, 0xFF}, {0x45, 0x28, 0x3C, 0xFF}, {0x66, 0x39, 0x31, 0xFF},
+	{0x8F, 0x56, 0x3B, 0xFF}, {0xDF, 0x71, 0x26, 0xFF}, {0xD9, 0xA0, 0x66, 0xFF}, {0xEE, 0xC3, 0x9A, 0xFF},
+	{0xFB, 0xF2, 0x36, 0xFF}, {0x99, 0xE5, 0x50, 0xFF}, {0x6A, 0xBE, 0x30, 0xFF}, {0x37, 0x94, 0x6E, 0xFF},
+	{0x4B, 0x69, 0x2F, 0xFF}, {0x52, 0x4B, 0x24, 0xFF}, {0x32, 0x3C, 0x39, 0xFF}, {0x3F, 0x3F, 0x74, 0xFF},
+	{0x30, 0x60, 0x82, 0xFF}, {0x5B, 0x6E, 0xE1, 0xFF}, {0x63, 0x9B, 0xFF, 0xFF}, {0x5F, 0xCD, 0xE4, 0xFF},
+	{0xCB, 0xDB, 0xFC, 0xFF}, {0xFF, 0xFF, 0xFF, 0xFF}, {0x9B, 0xAD, 0xB7, 0xFF}, {0x84, 0x7E, 0x87, 0xFF},
+	{0x69, 0x6A, 0x6A, 0xFF}, {0x59, 0x56, 0x52, 0xFF}, {0x76, 0x42, 0x8A, 0xFF}, {0xAC, 0x32, 0x32, 0xFF},
+	{0xD9, 0x57, 0x63, 0xFF}, {0xD7, 0x7B, 0xBA, 0xFF}, {0x8F, 0x97, 0x4A, 0xFF}, {0x8A, 0x6F, 0x30, 0xFF},
+}
+
+// PaletteCSS is the CSS named colors, in alphabetical order of their names.
+var PaletteCSS = func() Palette {
+	palette := make(Palette, len(colornames.Names))
+	for i, name := range colornames.Names {
+		palette[i] = colornames.Map[name]
+	}
+	return palette
+}()
+
+// CSSColor returns the CSS named color with the given case-insensitive name.
+func CSSColor(name string) (Color, bool) {
+	c, ok := colornames.Map[strings.ToLower(name)]
+	return c, ok
+}
+
+// LoadPalette reads a palette from r. GIMP .gpl, JASC .pal, and hex files with a color such as "ff8800" or "#ff8800" on each line are supported.
+func LoadPalette(r io.Reader) (Palette, error) {
+	scanner := bufio.NewScanner(r)
+	var palette Palette
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, ErrPalette
+	}
+
+	switch {
+	case lines[0] == "GIMP Palette":
+		for _, line := range lines[1:] {
+			if line[0] == '#' || strings.HasPrefix(line, "Name:") || strings.HasPrefix(line, "Columns:") {
+				continue
+			}
+			c, err := parsePaletteRGB(line)
+			if err != nil {
+				return nil, err
+			}
+			palette = append(palette, c)
+		}
+	case lines[0] == "JASC-PAL":
+		// The header is followed by the version and the number of colors.
+		if len(lines) < 3 {
+			return nil, ErrPalette
+		}
+		for _, line := range lines[3:] {
+			c, err := parsePaletteRGB(line)
+			if err != nil {
+				return nil, err
+			}
+			palette = append(palette, c)
+		}
+	default:
+		for _, line := range lines {
+			if line[0] == ';' || strings.HasPrefix(line, "//") {
+				continue
+			}
+			c, err := parsePaletteHex(line)
+			if err != nil {
+				return nil, err
+			}
+			palette = append(palette, c)
+		}
+	}
+	return palette, nil
+}
+
+// parsePaletteRGB parses a line starting with red, green, and blue decimal values separated by whitespace. Anything after them, such as a color name, is ignored.
+func parsePaletteRGB(line string) (Color, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return ColorNone, ErrPalette
+	}
+	var channels [3]uint8
+	for i := range channels {
+		v, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return ColorNone, ErrPalette
+		}
+		channels[i] = uint8(v)
+	}
+	return Color{channels[0], channels[1], channels[2], 0xFF}, nil
+}
+
+// parsePaletteHex parses a line containing an RRGGBB hex color, optionally prefixed with '#'.
+func parsePaletteHex(line string) (Color, error) {
+	line = strings.TrimPrefix(strings.Fields(line)[0], "#")
+	if len(line) != 6 {
+		return ColorNone, ErrPalette
+	}
+	v, err := strconv.ParseUint(line, 16, 32)
+	if err != nil {
+		return ColorNone, ErrPalette
+	}
+	return Color{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xFF}, nil
+}
@@ -0,0 +1,194 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Particle is a single particle of a ParticleEmitter. Positions are in cells and velocities in cells per second.
+type Particle struct {
+	X, Y     float64
+	VX, VY   float64
+	Age      time.Duration
+	Lifetime time.Duration
+}
+
+// progress returns how far through its lifetime the particle is, from 0 to 1.
+func (particle *Particle) progress() float64 {
+	if particle.Lifetime <= 0 {
+		return 1
+	}
+	return math.Min(1, float64(particle.Age)/float64(particle.Lifetime))
+}
+
+// ParticleEmitter is an Animation that emits particles from a point, or from an area if Width or Height are set, and draws them over the Screen's cells each tick. A particle's rune and foreground are chosen from Runes and Gradient by how far through its lifetime it is. Particles are emitted using Rand, or Random if it is nil, so emitters are deterministic when seeded.
+type ParticleEmitter struct {
+	X, Y, Width, Height float64
+	// Rate is the number of particles emitted per second, and Burst the number emitted at once when the emitter starts.
+	Rate  float64
+	Burst int
+	// Duration is how long particles are emitted for, or 0 to emit until stopped. The emitter finishes once its particles have died.
+	Duration time.Duration
+	// Angle is the direction particles are emitted in, in radians, and Spread the random variation in either direction.
+	Angle, Spread float64
+	// Speed is the speed particles are emitted at, in cells per second, and SpeedVariance the random variation in either direction.
+	Speed, SpeedVariance float64
+	// AccelerationX and AccelerationY are applied to each particle's velocity, in cells per second per second, such as for gravity.
+	AccelerationX, AccelerationY float64
+	Lifetime, LifetimeVariance   time.Duration
+	Runes                        []rune
+	Gradient                     []Color
+	Rand                         *rand.Rand
+
+	particles   []Particle
+	elapsed     time.Duration
+	accumulator float64
+	started     bool
+	drawn       map[[2]int]particleCell
+}
+
+// particleCell records a cell drawn over by particles so that it can be restored.
+type particleCell struct {
+	under Cell
+	rune  rune
+	style Style
+}
+
+// Particles returns the emitter's living particles.
+func (emitter *ParticleEmitter) Particles() []Particle {
+	return emitter.particles
+}
+
+// Step emits, moves, and ages the particles, then draws them.
+func (emitter *ParticleEmitter) Step(screen *Screen, elapsed time.Duration) bool {
+	delta := elapsed - emitter.elapsed
+	emitter.elapsed = elapsed
+	seconds := delta.Seconds()
+
+	emitting := emitter.Duration <= 0 || elapsed < emitter.Duration
+	if !emitter.started {
+		emitter.started = true
+		for i := 0; i < emitter.Burst; i++ {
+			emitter.emit()
+		}
+	}
+	if emitting && emitter.Rate > 0 {
+		emitter.accumulator += emitter.Rate * seconds
+		for emitter.accumulator >= 1 {
+			emitter.accumulator--
+			emitter.emit()
+		}
+	}
+
+	living := emitter.particles[:0]
+	for _, particle := range emitter.particles {
+		particle.Age += delta
+		if particle.Age >= particle.Lifetime {
+			continue
+		}
+		particle.VX += emitter.AccelerationX * seconds
+		particle.VY += emitter.AccelerationY * seconds
+		particle.X += particle.VX * seconds
+		particle.Y += particle.VY * seconds
+		living = append(living, particle)
+	}
+	emitter.particles = living
+
+	emitter.restore(screen)
+	emitter.draw(screen)
+
+	return !emitting && len(emitter.particles) == 0
+}
+
+// End removes the particles and restores the cells they were drawn over.
+func (emitter *ParticleEmitter) End(screen *Screen) {
+	emitter.particles = nil
+	emitter.restore(screen)
+}
+
+// emit adds a new particle.
+func (emitter *ParticleEmitter) emit() {
+	r := emitter.Rand
+	if r == nil {
+		r = Random
+	}
+	angle := emitter.Angle + (r.Float64()*2-1)*emitter.Spread
+	speed := emitter.Speed + (r.Float64()*2-1)*emitter.SpeedVariance
+	lifetime := emitter.Lifetime
+	if emitter.LifetimeVariance > 0 {
+		lifetime += time.Duration((r.Float64()*2 - 1) * float64(emitter.LifetimeVariance))
+	}
+	emitter.particles = append(emitter.particles, Particle{
+		X:        emitter.X + r.Float64()*emitter.Width,
+		Y:        emitter.Y + r.Float64()*emitter.Height,
+		VX:       math.Cos(angle) * speed,
+		VY:       math.Sin(angle) * speed,
+		Lifetime: lifetime,
+	})
+}
+
+// draw draws each particle into the cell it is in, with the cell's offset set to the particle's position within it.
+func (emitter *ParticleEmitter) draw(screen *Screen) {
+	if len(emitter.Runes) == 0 {
+		return
+	}
+	if emitter.drawn == nil {
+		emitter.drawn = make(map[[2]int]particleCell)
+	}
+	for _, particle := range emitter.particles {
+		cellX, cellY := math.Floor(particle.X), math.Floor(particle.Y)
+		x, y := int(cellX), int(cellY)
+		under, err := screen.pendingCell(x, y)
+		if err != nil {
+			continue
+		}
+		key := [2]int{x, y}
+		if previous, ok := emitter.drawn[key]; ok {
+			under = previous.under
+		}
+		progress := particle.progress()
+		index := int(progress * float64(len(emitter.Runes)))
+		if index >= len(emitter.Runes) {
+			index = len(emitter.Runes) - 1
+		}
+		style := under.PendingStyle
+		if len(emitter.Gradient) > 0 {
+			style.Foreground = NewGradient(emitter.Gradient...).At(progress)
+		}
+		r := emitter.Runes[index]
+		screen.DrawRune(x, y, r, style)
+		screen.SetOffset(x, y, particle.X-cellX-0.5, particle.Y-cellY-0.5)
+		emitter.drawn[key] = particleCell{under: under, rune: r, style: style}
+	}
+}
+
+// restore restores the cells drawn over by particles, unless something else has since drawn to them.
+func (emitter *ParticleEmitter) restore(screen *Screen) {
+	for key, drawn := range emitter.drawn {
+		cell, err := screen.pendingCell(key[0], key[1])
+		if err == nil && cell.PendingRune == drawn.rune && cell.PendingStyle == drawn.style {
+			screen.DrawRune(key[0], key[1], drawn.under.PendingRune, drawn.under.PendingStyle)
+			screen.SetOffset(key[0], key[1], drawn.under.PendingOffsetX, drawn.under.PendingOffsetY)
+		}
+		delete(emitter.drawn, key)
+	}
+}
@@ -110,6 +110,14 @@ func (p *PathAStar) Compute(oX, oY int, tX, tY int) (steps []Step) {
 	if oX == tX && oY == tY {
 		return
 	}
+	// Clear any state left over from a previous computation.
+	for _, row := range p.nodes {
+		for _, node := range row {
+			node.parent = nil
+			node.gCost = math.MaxFloat64
+			node.fCost = math.MaxFloat64
+		}
+	}
 	// Set our first node's costs.
 	p.nodes[oY][oX].gCost = 0
 	p.nodes[oY][oX].fCost = p.calculateH(oX, oY, tX, tY)
@@ -0,0 +1,99 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package pathing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// pathBinaryVersion is the version of the encoding written by PathAStar.MarshalBinary.
+const pathBinaryVersion = 1
+
+// ErrPathBinary is returned when decoding invalid path data.
+var ErrPathBinary = errors.New("invalid path data")
+
+// MarshalBinary encodes the path's size, whether diagonals are allowed, and each node's movement cost. A custom heuristics function is not encoded and must be set again after decoding.
+func (p *PathAStar) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(pathBinaryVersion)
+	binary.Write(&buf, binary.LittleEndian, uint32(p.width))
+	binary.Write(&buf, binary.LittleEndian, uint32(p.height))
+	if p.diagonals {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	for y := range p.nodes {
+		for _, node := range p.nodes[y] {
+			binary.Write(&buf, binary.LittleEndian, node.mCost)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes data encoded by MarshalBinary.
+func (p *PathAStar) UnmarshalBinary(data []byte) error {
+	if len(data) < 10 || data[0] != pathBinaryVersion {
+		return ErrPathBinary
+	}
+	width := int(binary.LittleEndian.Uint32(data[1:]))
+	height := int(binary.LittleEndian.Uint32(data[5:]))
+	diagonals := data[9] != 0
+	data = data[10:]
+	if len(data) != width*height*4 {
+		return ErrPathBinary
+	}
+	p.Resize(width, height)
+	p.diagonals = diagonals
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p.nodes[y][x] = &NodeAStar{
+				x:     x,
+				y:     y,
+				fCost: math.MaxFloat64,
+				gCost: math.MaxFloat64,
+				hCost: math.MaxFloat64,
+				mCost: binary.LittleEndian.Uint32(data),
+			}
+			data = data[4:]
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the step's position as two little-endian int32s.
+func (s Step) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint32(data, uint32(int32(s.x)))
+	binary.LittleEndian.PutUint32(data[4:], uint32(int32(s.y)))
+	return data, nil
+}
+
+// UnmarshalBinary decodes a step encoded by MarshalBinary.
+func (s *Step) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return ErrPathBinary
+	}
+	s.x = int(int32(binary.LittleEndian.Uint32(data)))
+	s.y = int(int32(binary.LittleEndian.Uint32(data[4:])))
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package pathing
+
+import (
+	"reflect"
+	"testing"
+)
+
+// costs returns each node's movement cost, row by row.
+func costs(p *PathAStar) [][]uint32 {
+	result := make([][]uint32, len(p.nodes))
+	for y := range p.nodes {
+		result[y] = make([]uint32, len(p.nodes[y]))
+		for x, node := range p.nodes[y] {
+			result[y][x] = node.mCost
+		}
+	}
+	return result
+}
+
+func TestPathAStarBinary(t *testing.T) {
+	walls := func(x, y int) uint32 {
+		if x == 2 && y < 3 {
+			return MaximumCost
+		}
+		return uint32(1 + x + y*10)
+	}
+	tests := []struct {
+		name          string
+		width, height int
+		diagonals     bool
+	}{
+		{"empty", 0, 0, false},
+		{"single node", 1, 1, true},
+		{"walls", 5, 4, false},
+		{"walls with diagonals", 5, 4, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := NewPathAStarFromFunc(test.width, test.height, walls).(*PathAStar)
+			original.AllowDiagonals(test.diagonals)
+			data, err := original.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded := NewPathAStarFromFunc(2, 7, walls).(*PathAStar)
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.width != test.width || decoded.height != test.height || decoded.diagonals != test.diagonals {
+				t.Errorf("got %dx%d diagonals %v, want %dx%d diagonals %v", decoded.width, decoded.height, decoded.diagonals, test.width, test.height, test.diagonals)
+			}
+			if got, want := costs(decoded), costs(original); !reflect.DeepEqual(got, want) {
+				t.Errorf("got costs %v, want %v", got, want)
+			}
+			if test.width > 4 {
+				if got, want := decoded.Compute(0, 0, 4, 0), original.Compute(0, 0, 4, 0); !reflect.DeepEqual(got, want) {
+					t.Errorf("got path %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPathAStarBinaryErrors(t *testing.T) {
+	valid, _ := NewPathAStarFromFunc(3, 2, func(x, y int) uint32 { return 1 }).(*PathAStar).MarshalBinary()
+	version := append([]byte(nil), valid...)
+	version[0]++
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:9]},
+		{"version", version},
+		{"truncated nodes", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &PathAStar{}
+			if err := p.UnmarshalBinary(test.data); err != ErrPathBinary {
+				t.Errorf("got %v, want ErrPathBinary", err)
+			}
+		})
+	}
+}
+
+func TestStepBinary(t *testing.T) {
+	tests := []Step{{}, {x: 3, y: 9}, {x: -1, y: -70000}, {x: 1<<31 - 1, y: -1 << 31}}
+	for _, want := range tests {
+		data, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Step
+		if err := got.UnmarshalBinary(data); err != nil || got != want {
+			t.Errorf("got %v %v, want %v", got, err, want)
+		}
+	}
+	var step Step
+	for _, data := range [][]byte{nil, make([]byte, 7), make([]byte, 9)} {
+		if err := step.UnmarshalBinary(data); err != ErrPathBinary {
+			t.Errorf("got %v decoding %d bytes, want ErrPathBinary", err, len(data))
+		}
+	}
+}
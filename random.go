@@ -1,14 +1,70 @@
 package goro
 
 import (
+	"encoding/binary"
+	"errors"
 	"math/rand"
 	"time"
 )
 
-// SetSeed sets the current seed to the provided int64 seed.
+// ErrRandomState is returned when restoring a RandomSource from invalid data, or when saving the state of Random while it does not use a RandomSource.
+var ErrRandomState = errors.New("invalid random source state")
+
+// RandomSource is a rand.Source64 whose state can be saved and restored with MarshalBinary and UnmarshalBinary, allowing a game's random sequence to continue across saves. It uses the splitmix64 algorithm.
+type RandomSource struct {
+	state uint64
+}
+
+// NewRandomSource returns a RandomSource seeded with seed.
+func NewRandomSource(seed int64) *RandomSource {
+	return &RandomSource{state: uint64(seed)}
+}
+
+// Seed resets the source to the given seed.
+func (source *RandomSource) Seed(seed int64) {
+	source.state = uint64(seed)
+}
+
+// Uint64 returns the next pseudo-random uint64.
+func (source *RandomSource) Uint64() uint64 {
+	source.state += 0x9E3779B97F4A7C15
+	z := source.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Int63 returns the next pseudo-random non-negative int64.
+func (source *RandomSource) Int63() int64 {
+	return int64(source.Uint64() >> 1)
+}
+
+// MarshalBinary encodes the source's state.
+func (source *RandomSource) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, source.state)
+	return data, nil
+}
+
+// UnmarshalBinary restores a state encoded by MarshalBinary.
+func (source *RandomSource) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return ErrRandomState
+	}
+	source.state = binary.LittleEndian.Uint64(data)
+	return nil
+}
+
+// SetSeed sets the current seed to the provided int64 seed, using the standard library's source. Its state cannot be saved with RandomState.
 func SetSeed(seed int64) {
-	source := rand.NewSource(seed)
-	Random = rand.New(source)
+	randomSource = nil
+	Random = rand.New(rand.NewSource(seed))
+}
+
+// SetSerializableSeed sets the current seed to the provided int64 seed, using a RandomSource so that its state can be saved with RandomState. The sequence differs from that of SetSeed with the same seed.
+func SetSerializableSeed(seed int64) {
+	randomSource = NewRandomSource(seed)
+	Random = rand.New(randomSource)
 }
 
 // RandomSeed returns a randomized int64 seed based upon time.
@@ -16,5 +72,27 @@ func RandomSeed() int64 {
 	return time.Now().UnixNano()
 }
 
+// RandomState returns the state of Random's source, for saving. It returns ErrRandomState unless Random was last seeded with SetSerializableSeed or SetRandomState.
+func RandomState() ([]byte, error) {
+	if randomSource == nil {
+		return nil, ErrRandomState
+	}
+	return randomSource.MarshalBinary()
+}
+
+// SetRandomState restores the state of Random's source from data returned by RandomState.
+func SetRandomState(data []byte) error {
+	source := &RandomSource{}
+	if err := source.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	randomSource = source
+	Random = rand.New(randomSource)
+	return nil
+}
+
+// randomSource is the source used by Random if it can be saved, or nil.
+var randomSource *RandomSource
+
 // Random is our global default for random calls.
 var Random = rand.New(rand.NewSource(0))
@@ -0,0 +1,187 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/kettek/goro/glyphs"
+	"github.com/kettek/goro/resources"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// ErrRasterizerGlyphs is returned when a Rasterizer has no default glyphs to size its cells with.
+var ErrRasterizerGlyphs = errors.New("rasterizer has no default glyphs")
+
+// Rasterizer renders a Screen's committed cells to an image without requiring a graphical backend.
+type Rasterizer struct {
+	Foreground, Background Color // Colors used when both the cell and the Screen use ColorNone.
+	glyphs                 []glyphs.Glyphs
+	bitmapCache            map[bitmapGlyphKey]*image.Alpha
+}
+
+type bitmapGlyphKey struct {
+	id            glyphs.ID
+	r             rune
+	width, height int
+}
+
+// NewRasterizer returns a Rasterizer using goRo's built-in font at the provided size for its default glyphs.
+func NewRasterizer(size float64) (*Rasterizer, error) {
+	rasterizer := newRasterizer()
+	ttfGlyphs, err := glyphs.LoadTruetypeFromBytes(resources.GoroTTF)
+	if err != nil {
+		return nil, err
+	}
+	ttfGlyphs.SetSize(size)
+	rasterizer.glyphs[glyphs.Default] = ttfGlyphs
+	return rasterizer, nil
+}
+
+// newRasterizer returns a Rasterizer without any glyphs.
+func newRasterizer() *Rasterizer {
+	return &Rasterizer{
+		Foreground:  ColorWhite,
+		Background:  ColorBlack,
+		glyphs:      make([]glyphs.Glyphs, 256), // One for every glyphs.ID.
+		bitmapCache: make(map[bitmapGlyphKey]*image.Alpha),
+	}
+}
+
+// SetGlyphs sets the Glyphs used for cells with the given ID. The default glyphs determine the cell size.
+func (rasterizer *Rasterizer) SetGlyphs(id glyphs.ID, g glyphs.Glyphs) {
+	rasterizer.glyphs[id] = g
+	for key := range rasterizer.bitmapCache {
+		if key.id == id {
+			delete(rasterizer.bitmapCache, key)
+		}
+	}
+}
+
+// CellSize returns the size of a single cell in pixels.
+func (rasterizer *Rasterizer) CellSize() (int, int) {
+	if rasterizer.glyphs[glyphs.Default] == nil {
+		return 0, 0
+	}
+	return rasterizer.glyphs[glyphs.Default].Width(), rasterizer.glyphs[glyphs.Default].Height()
+}
+
+// Rasterize renders the Screen's committed cells to a new image.
+func (rasterizer *Rasterizer) Rasterize(screen *Screen) (*image.RGBA, error) {
+	cellWidth, cellHeight := rasterizer.CellSize()
+	if cellWidth == 0 || cellHeight == 0 {
+		return nil, ErrRasterizerGlyphs
+	}
+
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+
+	rows := len(screen.cells)
+	columns := 0
+	if rows > 0 {
+		columns = len(screen.cells[0])
+	}
+	target := image.NewRGBA(image.Rect(0, 0, columns*cellWidth, rows*cellHeight))
+
+	for y := range screen.cells {
+		for x := range screen.cells[y] {
+			cell := &screen.cells[y][x]
+			fg, bg := rasterizer.cellColors(screen, cell.Style)
+			rect := image.Rect(x*cellWidth, y*cellHeight, (x+1)*cellWidth, (y+1)*cellHeight)
+			draw.Draw(target, rect, image.NewUniform(bg), image.ZP, draw.Src)
+			if cell.Rune == rune(0) || cell.Rune == ' ' {
+				continue
+			}
+			glyphSet := rasterizer.glyphs[cell.Glyphs]
+			if glyphSet == nil {
+				glyphSet = rasterizer.glyphs[glyphs.Default]
+			}
+			switch glyphSet := glyphSet.(type) {
+			case *glyphs.Truetype:
+				bounds, _, _ := glyphSet.Normal.GlyphBounds(cell.Rune)
+				drawer := font.Drawer{
+					Dst:  target,
+					Src:  image.NewUniform(fg),
+					Face: glyphSet.Normal,
+					Dot:  fixed.P(x*cellWidth+(cellWidth/2-bounds.Max.X.Round()/2), y*cellHeight+glyphSet.Ascent()),
+				}
+				drawer.DrawString(string(cell.Rune))
+			case *glyphs.Bitmap:
+				mask := rasterizer.bitmapGlyph(cell.Glyphs, glyphSet, cell.Rune, cellWidth, cellHeight)
+				if mask != nil {
+					draw.DrawMask(target, rect, image.NewUniform(fg), image.ZP, mask, image.ZP, draw.Over)
+				}
+			}
+		}
+	}
+	return target, nil
+}
+
+// WritePNG renders the Screen's committed cells and writes them to w as a PNG.
+func (rasterizer *Rasterizer) WritePNG(w io.Writer, screen *Screen) error {
+	img, err := rasterizer.Rasterize(screen)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// cellColors returns the foreground and background colors to use for a style, falling back to the Screen's and then the Rasterizer's defaults.
+func (rasterizer *Rasterizer) cellColors(screen *Screen, style Style) (fg, bg Color) {
+	fg, bg = style.Foreground, style.Background
+	if fg == ColorNone {
+		fg = screen.Foreground
+	}
+	if fg == ColorNone {
+		fg = rasterizer.Foreground
+	}
+	if bg == ColorNone {
+		bg = screen.Background
+	}
+	if bg == ColorNone {
+		bg = rasterizer.Background
+	}
+	if style.Reverse {
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+// bitmapGlyph returns the coverage mask for a bitmap glyph scaled to the cell size.
+func (rasterizer *Rasterizer) bitmapGlyph(id glyphs.ID, bitmap *glyphs.Bitmap, r rune, width, height int) *image.Alpha {
+	key := bitmapGlyphKey{id: id, r: r, width: width, height: height}
+	if mask, ok := rasterizer.bitmapCache[key]; ok {
+		return mask
+	}
+	src, ok := bitmap.Glyph(r)
+	if !ok {
+		rasterizer.bitmapCache[key] = nil
+		return nil
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	xdraw.NearestNeighbor.Scale(mask, mask.Bounds(), bitmap.Mask(), src, xdraw.Src, nil)
+	rasterizer.bitmapCache[key] = mask
+	return mask
+}
@@ -0,0 +1,94 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"time"
+
+	"github.com/kettek/goro/glyphs"
+)
+
+// FrameRecorder is an interface for receiving the cells committed by each Screen.Flush.
+type FrameRecorder interface {
+	RecordFrame(frame Frame)
+}
+
+// Frame is the set of cells committed by a single Screen.Flush.
+type Frame struct {
+	Time          time.Time
+	Columns, Rows int
+	Full          bool // Whether Cells contains every cell of the Screen rather than only the changed ones.
+	Cells         []FrameCell
+}
+
+// FrameCell is a single committed cell within a Frame.
+type FrameCell struct {
+	X, Y   int
+	Rune   rune
+	Style  Style
+	Glyphs glyphs.ID
+}
+
+// AddFrameRecorder adds a FrameRecorder to the Screen. The recorder immediately receives a full Frame of the Screen's committed cells, followed by the changed cells of each subsequent Flush.
+func (screen *Screen) AddFrameRecorder(recorder FrameRecorder) {
+	screen.cellsMutex.Lock()
+	screen.frameRecorders = append(screen.frameRecorders, recorder)
+
+	frame := screen.newFrame(true)
+	for y := range screen.cells {
+		for x := range screen.cells[y] {
+			frame.Cells = append(frame.Cells, FrameCell{
+				X:      x,
+				Y:      y,
+				Rune:   screen.cells[y][x].Rune,
+				Style:  screen.cells[y][x].Style,
+				Glyphs: screen.cells[y][x].Glyphs,
+			})
+		}
+	}
+	screen.recordMutex.Lock()
+	screen.cellsMutex.Unlock()
+	defer screen.recordMutex.Unlock()
+	recorder.RecordFrame(frame)
+}
+
+// RemoveFrameRecorder removes a previously added FrameRecorder from the Screen.
+func (screen *Screen) RemoveFrameRecorder(recorder FrameRecorder) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	for i, r := range screen.frameRecorders {
+		if r == recorder {
+			screen.frameRecorders = append(screen.frameRecorders[:i], screen.frameRecorders[i+1:]...)
+			return
+		}
+	}
+}
+
+// newFrame returns an empty Frame sized to the Screen.
+func (screen *Screen) newFrame(full bool) Frame {
+	frame := Frame{
+		Time: time.Now(),
+		Rows: len(screen.cells),
+		Full: full,
+	}
+	if frame.Rows > 0 {
+		frame.Columns = len(screen.cells[0])
+	}
+	return frame
+}
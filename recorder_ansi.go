@@ -0,0 +1,61 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"io"
+	"sync"
+)
+
+// ANSIRecorder is a FrameRecorder that writes each Frame to an io.Writer as ANSI escape sequences. Playing the output back in a terminal, such as with cat, reproduces the recorded frames.
+type ANSIRecorder struct {
+	w     io.Writer
+	enc   ansiEncoder
+	err   error
+	mutex sync.Mutex
+}
+
+// NewANSIRecorder returns an ANSIRecorder that writes to w.
+func NewANSIRecorder(w io.Writer) *ANSIRecorder {
+	recorder := &ANSIRecorder{w: w}
+	recorder.enc.reset()
+	return recorder
+}
+
+// RecordFrame writes the changed cells of frame. Full frames clear the terminal first.
+func (recorder *ANSIRecorder) RecordFrame(frame Frame) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if recorder.err != nil {
+		return
+	}
+	if frame.Full {
+		recorder.enc.clearScreen()
+	}
+	recorder.enc.encodeFrame(frame)
+	_, recorder.err = recorder.w.Write(recorder.enc.buf)
+	recorder.enc.buf = recorder.enc.buf[:0]
+}
+
+// Err returns the first error encountered while writing, if any.
+func (recorder *ANSIRecorder) Err() error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return recorder.err
+}
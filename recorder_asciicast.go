@@ -0,0 +1,122 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AsciicastRecorder is a FrameRecorder that writes an asciinema v2 .cast recording, with each Frame becoming an output event timestamped relative to the first Frame.
+type AsciicastRecorder struct {
+	Title         string
+	w             io.Writer
+	enc           ansiEncoder
+	start         time.Time
+	columns, rows int
+	started       bool
+	err           error
+	mutex         sync.Mutex
+}
+
+type asciicastHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Title     string `json:"title,omitempty"`
+}
+
+// NewAsciicastRecorder returns an AsciicastRecorder that writes to w using the provided recording title.
+func NewAsciicastRecorder(w io.Writer, title string) *AsciicastRecorder {
+	recorder := &AsciicastRecorder{
+		Title: title,
+		w:     w,
+	}
+	recorder.enc.reset()
+	return recorder
+}
+
+// RecordFrame writes the header on the first call, followed by an output event for the frame's cells. Changes in the frame's size are written as resize events.
+func (recorder *AsciicastRecorder) RecordFrame(frame Frame) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if recorder.err != nil {
+		return
+	}
+	if !recorder.started {
+		recorder.started = true
+		recorder.start = frame.Time
+		recorder.columns, recorder.rows = frame.Columns, frame.Rows
+		header, err := json.Marshal(asciicastHeader{
+			Version:   2,
+			Width:     frame.Columns,
+			Height:    frame.Rows,
+			Timestamp: frame.Time.Unix(),
+			Title:     recorder.Title,
+		})
+		if err != nil {
+			recorder.err = err
+			return
+		}
+		if recorder.err = recorder.writeLine(header); recorder.err != nil {
+			return
+		}
+	}
+	elapsed := frame.Time.Sub(recorder.start).Seconds()
+
+	if frame.Columns != recorder.columns || frame.Rows != recorder.rows {
+		recorder.columns, recorder.rows = frame.Columns, frame.Rows
+		if recorder.err = recorder.writeEvent(elapsed, "r", strconv.Itoa(frame.Columns)+"x"+strconv.Itoa(frame.Rows)); recorder.err != nil {
+			return
+		}
+	}
+
+	if frame.Full {
+		recorder.enc.clearScreen()
+	}
+	recorder.enc.encodeFrame(frame)
+	recorder.err = recorder.writeEvent(elapsed, "o", string(recorder.enc.buf))
+	recorder.enc.buf = recorder.enc.buf[:0]
+}
+
+// writeEvent writes a single asciicast event line.
+func (recorder *AsciicastRecorder) writeEvent(elapsed float64, code string, data string) error {
+	event, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		return err
+	}
+	return recorder.writeLine(event)
+}
+
+// writeLine writes b followed by a newline.
+func (recorder *AsciicastRecorder) writeLine(b []byte) error {
+	_, err := recorder.w.Write(append(b, '\n'))
+	return err
+}
+
+// Err returns the first error encountered while writing, if any.
+func (recorder *AsciicastRecorder) Err() error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return recorder.err
+}
@@ -0,0 +1,276 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+// scrollOp is a scroll of a Screen's region, in the Screen's cells.
+type scrollOp struct {
+	rect   Rect
+	dx, dy int
+}
+
+// moves returns whether the cell at x and y was moved into place by the scroll, rather than filled.
+func (op scrollOp) moves(x, y int) bool {
+	return op.rect.Contains(x, y) && op.rect.Contains(x-op.dx, y-op.dy)
+}
+
+// Scroll shifts the cells of the rectangle by dx and dy, filling the cells left behind with the rune and style. Cells shifted outside of the rectangle are discarded. When the Screen is flushed, the ebiten and web backends move the displayed region rather than redrawing each moved cell, as does telnet for full-width vertical scrolls. tcell has no way to move a region, so it redraws the moved cells.
+func (screen *Screen) Scroll(rect Rect, dx, dy int, r rune, s Style) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	rect = rect.Intersect(Rect{0, 0, screen.Columns, screen.Rows})
+	if rect.Empty() || (dx == 0 && dy == 0) {
+		return
+	}
+	op := scrollOp{rect: rect, dx: dx, dy: dy}
+
+	// Copy in the opposite direction of the scroll so that no source cell is overwritten before it is moved.
+	xs, ys := rangeAway(rect.X, rect.Width, dx), rangeAway(rect.Y, rect.Height, dy)
+	for _, y := range ys {
+		for _, x := range xs {
+			cell := &screen.cells[y][x]
+			if op.moves(x, y) {
+				src := &screen.cells[y-dy][x-dx]
+				cell.PendingRune = src.PendingRune
+				cell.PendingStyle = src.PendingStyle
+				cell.PendingGlyphs = src.PendingGlyphs
+				cell.PendingOffsetX, cell.PendingOffsetY = src.PendingOffsetX, src.PendingOffsetY
+				cell.PendingRole = src.PendingRole
+			} else {
+				cell.PendingRune = r
+				cell.PendingStyle = s
+				cell.PendingOffsetX, cell.PendingOffsetY = 0, 0
+				cell.PendingRole = ""
+			}
+			cell.Dirty = true
+		}
+	}
+
+	// Consecutive scrolls of the same region combine into one.
+	if n := len(screen.pendingScrolls); n > 0 && screen.pendingScrolls[n-1].rect == rect {
+		screen.pendingScrolls[n-1].dx += dx
+		screen.pendingScrolls[n-1].dy += dy
+	} else {
+		screen.pendingScrolls = append(screen.pendingScrolls, op)
+	}
+}
+
+// rangeAway returns the indices from start to start+length, ordered to begin at the end that d moves away from.
+func rangeAway(start, length, d int) []int {
+	indices := make([]int, length)
+	for i := range indices {
+		if d > 0 {
+			indices[i] = start + length - 1 - i
+		} else {
+			indices[i] = start + i
+		}
+	}
+	return indices
+}
+
+// commitScrolls checks the pending scroll, if there is only one, and keeps it for the backend if every moved cell is exactly what is displayed at its source. Anything else drawn since makes the backend redraw the cells instead. The cells lock must be held, and it must be called before the pending cells are committed.
+func (screen *Screen) commitScrolls() {
+	screen.scrolls = nil
+	pending := screen.pendingScrolls
+	screen.pendingScrolls = nil
+	if len(pending) != 1 {
+		return
+	}
+	op := pending[0]
+	for y := op.rect.Y; y < op.rect.Y+op.rect.Height; y++ {
+		for x := op.rect.X; x < op.rect.X+op.rect.Width; x++ {
+			if !op.moves(x, y) {
+				continue
+			}
+			cell, src := &screen.cells[y][x], &screen.cells[y-op.dy][x-op.dx]
+			if src.Redraw || src.OffsetX != 0 || src.OffsetY != 0 || cell.PendingOffsetX != 0 || cell.PendingOffsetY != 0 {
+				return
+			}
+			if cell.PendingRune != src.Rune || cell.PendingStyle != src.Style || cell.PendingGlyphs != src.Glyphs {
+				return
+			}
+		}
+	}
+	screen.scrolls = append(screen.scrolls, op)
+}
+
+// scroll calls blit with each Screen's committed scrolls, translated to backend cells, for backends that can move displayed regions. If blit moves a region, the moved cells are not redrawn. Scrolls of Screens that are damaged or overlapped by a Screen above them are left to be redrawn. It must be called before redraw.
+func (screens *Screens) scroll(blit func(rect Rect, dx, dy int) bool) {
+	screens.mutex.Lock()
+	list := append([]*Screen(nil), screens.screens...)
+	damaged := screens.damaged
+	screens.mutex.Unlock()
+
+	for i, screen := range list {
+		screen.cellsMutex.Lock()
+		for _, op := range screen.scrolls {
+			rect := Rect{op.rect.X + screen.X, op.rect.Y + screen.Y, op.rect.Width, op.rect.Height}
+			if damaged || overlapped(list[i+1:], rect) || !blit(rect, op.dx, op.dy) {
+				continue
+			}
+			for y := op.rect.Y; y < op.rect.Y+op.rect.Height; y++ {
+				for x := op.rect.X; x < op.rect.X+op.rect.Width; x++ {
+					if op.moves(x, y) {
+						screen.cells[y][x].Redraw = false
+					}
+				}
+			}
+		}
+		screen.scrolls = nil
+		screen.cellsMutex.Unlock()
+	}
+}
+
+// overlapped returns whether any of the Screens cover part of the rectangle.
+func overlapped(screens []*Screen, rect Rect) bool {
+	for _, screen := range screens {
+		if !rect.Intersect(Rect{screen.X, screen.Y, screen.Columns, screen.Rows}).Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// BlitOptions control how Blit copies cells.
+type BlitOptions struct {
+	// Transparent skips source cells that have no rune, or only a space, and no background, and keeps the destination's background under source cells without one.
+	Transparent bool
+	// UseColorKey skips source cells whose background is ColorKey.
+	UseColorKey bool
+	ColorKey    Color
+	// Rotate turns the copied cells clockwise by this many quarter turns. Cells are rotated, then flipped.
+	Rotate       int
+	FlipX, FlipY bool
+	// Tint, unless it is ColorNone, is multiplied into the foreground and background of each copied cell.
+	Tint Color
+	// Transform, if set, is applied to the style of each copied cell after tinting.
+	Transform func(s Style) Style
+}
+
+// size returns the size of a rectangle of width by height cells after rotation.
+func (options *BlitOptions) size(width, height int) (int, int) {
+	if options.quarterTurns()%2 == 1 {
+		return height, width
+	}
+	return width, height
+}
+
+// quarterTurns returns Rotate as 0 to 3 clockwise quarter turns.
+func (options *BlitOptions) quarterTurns() int {
+	return (options.Rotate%4 + 4) % 4
+}
+
+// source returns the position in a width by height source of the cell that lands at x and y of the destination.
+func (options *BlitOptions) source(x, y, width, height int) (int, int) {
+	destWidth, destHeight := options.size(width, height)
+	if options.FlipX {
+		x = destWidth - 1 - x
+	}
+	if options.FlipY {
+		y = destHeight - 1 - y
+	}
+	switch options.quarterTurns() {
+	case 1:
+		return y, height - 1 - x
+	case 2:
+		return width - 1 - x, height - 1 - y
+	case 3:
+		return width - 1 - y, x
+	}
+	return x, y
+}
+
+// CopyRect copies the cells of the rectangle to destX and destY within the Screen. The areas may overlap.
+func (screen *Screen) CopyRect(rect Rect, destX, destY int) error {
+	return screen.Blit(destX, destY, screen, rect, BlitOptions{})
+}
+
+// Blit copies the cells of the source rectangle of source, which may be the Screen itself, to destX and destY on the Screen. Cells are copied as drawn, whether or not they have been flushed.
+func (screen *Screen) Blit(destX, destY int, source *Screen, rect Rect, options BlitOptions) error {
+	// Copying the source first means only one lock is held at a time, so Screens may blit to each other without deadlocking.
+	cells := source.copyCells(rect)
+
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	width, height := options.size(rect.Width, rect.Height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := options.source(x, y, rect.Width, rect.Height)
+			screen.blitCell(destX+x, destY+y, cells[sy][sx], options)
+		}
+	}
+	return nil
+}
+
+// copyCells returns a copy of the cells within the rectangle. Cells outside of the Screen are returned empty, with ok cleared.
+func (screen *Screen) copyCells(rect Rect) [][]blitSource {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	cells := make([][]blitSource, MaxInt(rect.Height, 0))
+	for y := range cells {
+		cells[y] = make([]blitSource, MaxInt(rect.Width, 0))
+		for x := range cells[y] {
+			if cell, err := screen.getCell(rect.X+x, rect.Y+y); err == nil {
+				cells[y][x] = blitSource{cell: cell, ok: true}
+			}
+		}
+	}
+	return cells
+}
+
+// blitSource is a copied cell, and whether it was within its Screen.
+type blitSource struct {
+	cell Cell
+	ok   bool
+}
+
+// blitCell draws a copied cell at x and y. The cells lock must be held.
+func (screen *Screen) blitCell(x, y int, src blitSource, options BlitOptions) {
+	if !src.ok || screen.checkBounds(x, y) != nil || !screen.inClip(x, y) {
+		return
+	}
+	r, s := src.cell.PendingRune, src.cell.PendingStyle
+	if options.UseColorKey && s.Background == options.ColorKey {
+		return
+	}
+	if options.Transparent {
+		if s.Background == ColorNone && (r == 0 || r == ' ') {
+			return
+		}
+		if s.Background == ColorNone {
+			s.Background = screen.cells[y][x].PendingStyle.Background
+		}
+	}
+	if options.Tint != ColorNone {
+		if s.Foreground != ColorNone {
+			s.Foreground = BlendMultiply(s.Foreground, options.Tint)
+		}
+		if s.Background != ColorNone {
+			s.Background = BlendMultiply(s.Background, options.Tint)
+		}
+	}
+	if options.Transform != nil {
+		s = options.Transform(s)
+	}
+	screen.drawRune(x, y, r, s)
+	screen.setGlyphsID(x, y, src.cell.PendingGlyphs)
+	screen.setOffset(x, y, src.cell.PendingOffsetX, src.cell.PendingOffsetY)
+	if s == src.cell.PendingStyle {
+		screen.cells[y][x].PendingRole = src.cell.PendingRole
+	}
+}
@@ -0,0 +1,175 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/kettek/goro/glyphs"
+)
+
+// rexPaintVersion is the version REXPaint writes to the start of .xp files.
+const rexPaintVersion = -1
+
+// REXPaintTransparent is the background color REXPaint uses to mark a cell as transparent.
+var REXPaintTransparent = Color{R: 0xFF, G: 0x00, B: 0xFF, A: 0xFF}
+
+// REXPaintMaxSize is the largest width or height accepted for a layer of an .xp file.
+const REXPaintMaxSize = 4096
+
+// ErrREXPaintLayers is returned when an .xp file contains no layers.
+var ErrREXPaintLayers = errors.New("REXPaint file has no layers")
+
+// ErrREXPaintSize is returned when a layer of an .xp file has a width or height that is not positive or is larger than REXPaintMaxSize.
+var ErrREXPaintSize = errors.New("REXPaint layer has an invalid size")
+
+type rexPaintCell struct {
+	Code       uint32
+	Foreground [3]uint8
+	Background [3]uint8
+}
+
+// LoadREXPaintLayers reads a REXPaint .xp file from r and returns each of its layers as a new virtual Screen. Transparent cells have their background set to ColorNone.
+func LoadREXPaintLayers(r io.Reader) (layers []*Screen, err error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var version, layerCount int32
+	if err = binary.Read(gz, binary.LittleEndian, &version); err != nil {
+		return nil, err
+	}
+	if err = binary.Read(gz, binary.LittleEndian, &layerCount); err != nil {
+		return nil, err
+	}
+	if layerCount <= 0 {
+		return nil, ErrREXPaintLayers
+	}
+	for i := int32(0); i < layerCount; i++ {
+		var width, height int32
+		if err = binary.Read(gz, binary.LittleEndian, &width); err != nil {
+			return nil, err
+		}
+		if err = binary.Read(gz, binary.LittleEndian, &height); err != nil {
+			return nil, err
+		}
+		if width <= 0 || height <= 0 || width > REXPaintMaxSize || height > REXPaintMaxSize {
+			return nil, ErrREXPaintSize
+		}
+		layer, err := NewScreen(int(width), int(height))
+		if err != nil {
+			return nil, err
+		}
+		// REXPaint stores its cells column by column.
+		column := make([]rexPaintCell, height)
+		for x := 0; x < int(width); x++ {
+			if err = binary.Read(gz, binary.LittleEndian, column); err != nil {
+				return nil, err
+			}
+			for y, xpCell := range column {
+				cell := &layer.cells[y][x]
+				if xpCell.Code < uint32(len(glyphs.CP437)) {
+					cell.PendingRune = glyphs.CP437[xpCell.Code]
+				} else {
+					cell.PendingRune = rune(xpCell.Code)
+				}
+				cell.PendingStyle.Foreground = Color{R: xpCell.Foreground[0], G: xpCell.Foreground[1], B: xpCell.Foreground[2], A: 0xFF}
+				cell.PendingStyle.Background = Color{R: xpCell.Background[0], G: xpCell.Background[1], B: xpCell.Background[2], A: 0xFF}
+				if cell.PendingStyle.Background == REXPaintTransparent {
+					cell.PendingStyle.Background = ColorNone
+				}
+				cell.Dirty = true
+			}
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// LoadREXPaint reads a REXPaint .xp file from r and returns its layers flattened into a single virtual Screen. Transparent cells in upper layers leave the cells beneath them untouched.
+func LoadREXPaint(r io.Reader) (*Screen, error) {
+	layers, err := LoadREXPaintLayers(r)
+	if err != nil {
+		return nil, err
+	}
+	screen := layers[0]
+	for _, layer := range layers[1:] {
+		for y := 0; y < MinInt(layer.Rows, screen.Rows); y++ {
+			for x := 0; x < MinInt(layer.Columns, screen.Columns); x++ {
+				cell := layer.cells[y][x]
+				if cell.PendingStyle.Background == ColorNone {
+					continue
+				}
+				screen.cells[y][x].PendingRune = cell.PendingRune
+				screen.cells[y][x].PendingStyle = cell.PendingStyle
+			}
+		}
+	}
+	return screen, nil
+}
+
+// SaveREXPaint writes the Screen to w as a single layer REXPaint .xp file. Runes without a Code Page 437 representation are written as '?', cells with a ColorNone background are marked as transparent, and a ColorNone foreground is written as the Screen's default foreground.
+func (screen *Screen) SaveREXPaint(w io.Writer) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+
+	gz := gzip.NewWriter(w)
+	height := len(screen.cells)
+	width := 0
+	if height > 0 {
+		width = len(screen.cells[0])
+	}
+	for _, v := range []int32{rexPaintVersion, 1, int32(width), int32(height)} {
+		if err := binary.Write(gz, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	column := make([]rexPaintCell, height)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			cell := &screen.cells[y][x]
+			code, ok := glyphs.RuneToCP437(cell.PendingRune)
+			if !ok {
+				code = '?'
+			}
+			fg := cell.PendingStyle.Foreground
+			if fg == ColorNone {
+				fg = screen.Foreground
+			}
+			bg := cell.PendingStyle.Background
+			if bg == ColorNone {
+				bg = REXPaintTransparent
+			}
+			column[y] = rexPaintCell{
+				Code:       uint32(code),
+				Foreground: [3]uint8{fg.R, fg.G, fg.B},
+				Background: [3]uint8{bg.R, bg.G, bg.B},
+			}
+		}
+		if err := binary.Write(gz, binary.LittleEndian, column); err != nil {
+			return err
+		}
+	}
+	return gz.Close()
+}
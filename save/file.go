@@ -0,0 +1,119 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package save
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrLocked is returned when a save file is locked by another process.
+var ErrLocked = errors.New("save file is locked")
+
+// Lock is an exclusive lock on a save file, held by a lock file next to it.
+type Lock struct {
+	path string
+}
+
+// LockFile locks the save file at path, returning ErrLocked if it is already locked. Holding the lock while a game is running prevents a second copy of the game from loading the same save. A lock left behind by a process that no longer exists, such as one that crashed, is taken over.
+func LockFile(path string) (*Lock, error) {
+	lockPath := path + ".lock"
+	file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil && os.IsExist(err) && staleLock(lockPath) {
+		os.Remove(lockPath)
+		file, err = os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	}
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+	fmt.Fprintf(file, "%d\n", os.Getpid())
+	if err := file.Close(); err != nil {
+		os.Remove(lockPath)
+		return nil, err
+	}
+	return &Lock{path: lockPath}, nil
+}
+
+// staleLock returns whether the lock file at lockPath holds the PID of a process that no longer exists.
+func staleLock(lockPath string) bool {
+	data, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 || pid == os.Getpid() {
+		return false
+	}
+	return !processExists(pid)
+}
+
+// Unlock releases the lock.
+func (lock *Lock) Unlock() error {
+	return os.Remove(lock.path)
+}
+
+// SaveFile writes the save to path. The save is written to a temporary file that replaces path once complete, so that a crash while saving does not destroy the previous save.
+func (schema *Schema) SaveFile(path string, save *Save) error {
+	file, err := os.OpenFile(filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := schema.Write(file, save); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// LoadFile reads the save at path. If the schema's DeleteOnLoad is set, the file is deleted once it has been read successfully.
+func (schema *Schema) LoadFile(path string) (*Save, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	save, err := schema.Read(file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	if schema.DeleteOnLoad {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return save, nil
+}
@@ -0,0 +1,267 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package save reads and writes versioned, compressed, and checksummed game state, with migrations for older versions and file locking and deletion for permadeath games.
+package save
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// Errors returned when reading saves.
+var (
+	ErrMagic     = errors.New("not a save file")
+	ErrChecksum  = errors.New("save checksum mismatch")
+	ErrVersion   = errors.New("save version is newer than the schema")
+	ErrMigration = errors.New("no migration for save version")
+	ErrNoSection = errors.New("save section does not exist")
+	ErrTooLarge  = errors.New("save payload is larger than MaxPayloadSize")
+)
+
+// MaxPayloadSize is the largest uncompressed payload Read accepts, guarding against saves that decompress to exhaust memory.
+const MaxPayloadSize = 256 << 20
+
+// magic starts every save.
+var magic = [8]byte{'G', 'O', 'R', 'O', 'S', 'A', 'V', 'E'}
+
+// Save is a game's state, stored as named sections of encoded data.
+type Save struct {
+	Version  uint32
+	Sections map[string][]byte
+}
+
+// Put stores the encoding of value as the named section.
+func (save *Save) Put(name string, value encoding.BinaryMarshaler) error {
+	data, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	save.PutBytes(name, data)
+	return nil
+}
+
+// Get decodes the named section into value.
+func (save *Save) Get(name string, value encoding.BinaryUnmarshaler) error {
+	data, ok := save.Sections[name]
+	if !ok {
+		return ErrNoSection
+	}
+	return value.UnmarshalBinary(data)
+}
+
+// PutGob stores the gob encoding of value as the named section, for values that do not implement encoding.BinaryMarshaler.
+func (save *Save) PutGob(name string, value interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return err
+	}
+	save.PutBytes(name, buf.Bytes())
+	return nil
+}
+
+// GetGob decodes the named section stored by PutGob into value, which must be a pointer.
+func (save *Save) GetGob(name string, value interface{}) error {
+	data, ok := save.Sections[name]
+	if !ok {
+		return ErrNoSection
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// PutBytes stores data as the named section.
+func (save *Save) PutBytes(name string, data []byte) {
+	if save.Sections == nil {
+		save.Sections = make(map[string][]byte)
+	}
+	save.Sections[name] = data
+}
+
+// Bytes returns the named section's data.
+func (save *Save) Bytes(name string) ([]byte, bool) {
+	data, ok := save.Sections[name]
+	return data, ok
+}
+
+// Delete removes the named section.
+func (save *Save) Delete(name string) {
+	delete(save.Sections, name)
+}
+
+// Has returns whether the named section exists.
+func (save *Save) Has(name string) bool {
+	_, ok := save.Sections[name]
+	return ok
+}
+
+// Migration upgrades a save from one version to the next by changing its sections.
+type Migration func(save *Save) error
+
+// Schema describes the current version of a game's saves and how to migrate older ones.
+type Schema struct {
+	Version uint32
+	// DeleteOnLoad removes save files once they have been loaded by LoadFile, so that a permadeath game cannot be reloaded to undo a death.
+	DeleteOnLoad bool
+	migrations   map[uint32]Migration
+}
+
+// NewSchema returns a Schema for the given current version.
+func NewSchema(version uint32) *Schema {
+	return &Schema{
+		Version:    version,
+		migrations: make(map[uint32]Migration),
+	}
+}
+
+// AddMigration sets the migration that upgrades saves of version from to version from+1.
+func (schema *Schema) AddMigration(from uint32, migration Migration) {
+	if schema.migrations == nil {
+		schema.migrations = make(map[uint32]Migration)
+	}
+	schema.migrations[from] = migration
+}
+
+// New returns an empty Save of the schema's version.
+func (schema *Schema) New() *Save {
+	return &Save{
+		Version:  schema.Version,
+		Sections: make(map[string][]byte),
+	}
+}
+
+// Write writes the save. The format is the magic "GOROSAVE", the version and a CRC-32 of the uncompressed payload as little-endian uint32s, then the gzip-compressed payload. The payload is a uint32 count of sections, each a uint32 name length, name, uint32 data length, and data, sorted by name.
+func (schema *Schema) Write(w io.Writer, save *Save) error {
+	var payload bytes.Buffer
+	names := make([]string, 0, len(save.Sections))
+	for name := range save.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	binary.Write(&payload, binary.LittleEndian, uint32(len(names)))
+	for _, name := range names {
+		binary.Write(&payload, binary.LittleEndian, uint32(len(name)))
+		payload.WriteString(name)
+		binary.Write(&payload, binary.LittleEndian, uint32(len(save.Sections[name])))
+		payload.Write(save.Sections[name])
+	}
+
+	header := make([]byte, 16)
+	copy(header, magic[:])
+	binary.LittleEndian.PutUint32(header[8:], save.Version)
+	binary.LittleEndian.PutUint32(header[12:], crc32.ChecksumIEEE(payload.Bytes()))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(payload.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads a save, verifies its checksum, and migrates it to the schema's version. Saves whose payload decompresses to more than MaxPayloadSize bytes return ErrTooLarge.
+func (schema *Schema) Read(r io.Reader) (*Save, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:8], magic[:]) {
+		return nil, ErrMagic
+	}
+	save := &Save{
+		Version:  binary.LittleEndian.Uint32(header[8:]),
+		Sections: make(map[string][]byte),
+	}
+	checksum := binary.LittleEndian.Uint32(header[12:])
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := ioutil.ReadAll(io.LimitReader(gz, MaxPayloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrTooLarge
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, ErrChecksum
+	}
+
+	readBytes := func() ([]byte, error) {
+		if len(payload) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		length := binary.LittleEndian.Uint32(payload)
+		payload = payload[4:]
+		if uint32(len(payload)) < length {
+			return nil, io.ErrUnexpectedEOF
+		}
+		data := payload[:length]
+		payload = payload[length:]
+		return data, nil
+	}
+	if len(payload) < 4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	count := binary.LittleEndian.Uint32(payload)
+	payload = payload[4:]
+	for i := uint32(0); i < count; i++ {
+		name, err := readBytes()
+		if err != nil {
+			return nil, err
+		}
+		data, err := readBytes()
+		if err != nil {
+			return nil, err
+		}
+		save.Sections[string(name)] = data
+	}
+
+	if err := schema.Migrate(save); err != nil {
+		return nil, err
+	}
+	return save, nil
+}
+
+// Migrate runs the migrations needed to bring save up to the schema's version.
+func (schema *Schema) Migrate(save *Save) error {
+	if save.Version > schema.Version {
+		return ErrVersion
+	}
+	for save.Version < schema.Version {
+		migration, ok := schema.migrations[save.Version]
+		if !ok {
+			return ErrMigration
+		}
+		if err := migration(save); err != nil {
+			return err
+		}
+		save.Version++
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package save
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// rawSave returns a save with the given header values and gzip-compressed payload, without checking that they agree.
+func rawSave(version, checksum uint32, payload []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(magic[:])
+	binary.Write(&buf, binary.LittleEndian, version)
+	binary.Write(&buf, binary.LittleEndian, checksum)
+	gz := gzip.NewWriter(&buf)
+	gz.Write(payload)
+	gz.Close()
+	return buf.Bytes()
+}
+
+// validSave returns a save with a correct checksum for payload.
+func validSave(version uint32, payload []byte) []byte {
+	return rawSave(version, crc32.ChecksumIEEE(payload), payload)
+}
+
+// payloadOf encodes the section count followed by each name and data pair, as Write does.
+func payloadOf(count uint32, pairs ...string) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, count)
+	for _, s := range pairs {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(s)))
+		buf.WriteString(s)
+	}
+	return buf.Bytes()
+}
+
+// point is a section value implementing encoding.BinaryMarshaler.
+type point struct {
+	X, Y int8
+}
+
+func (p point) MarshalBinary() ([]byte, error) {
+	return []byte{byte(p.X), byte(p.Y)}, nil
+}
+
+func (p *point) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return io.ErrUnexpectedEOF
+	}
+	p.X, p.Y = int8(data[0]), int8(data[1])
+	return nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections map[string][]byte
+	}{
+		{"no sections", nil},
+		{"one section", map[string][]byte{"player": []byte("hero")}},
+		{"empty section", map[string][]byte{"empty": {}}},
+		{"several sections", map[string][]byte{"b": {1, 2, 3}, "a": {4}, "c": bytes.Repeat([]byte{5}, 1000)}},
+		{"empty name", map[string][]byte{"": {6}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema := NewSchema(3)
+			save := schema.New()
+			for name, data := range test.sections {
+				save.PutBytes(name, data)
+			}
+			var buf bytes.Buffer
+			if err := schema.Write(&buf, save); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := schema.Read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded.Version != 3 {
+				t.Errorf("got version %d, want 3", loaded.Version)
+			}
+			if len(loaded.Sections) != len(test.sections) {
+				t.Fatalf("got %d sections, want %d", len(loaded.Sections), len(test.sections))
+			}
+			for name, data := range test.sections {
+				if got, ok := loaded.Bytes(name); !ok || !bytes.Equal(got, data) {
+					t.Errorf("section %q: got %v %v, want %v", name, got, ok, data)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTripValues(t *testing.T) {
+	schema := NewSchema(1)
+	save := schema.New()
+	if err := save.Put("point", point{-3, 7}); err != nil {
+		t.Fatal(err)
+	}
+	if err := save.PutGob("names", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := schema.Write(&buf, save); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := schema.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p point
+	if err := loaded.Get("point", &p); err != nil || p != (point{-3, 7}) {
+		t.Errorf("got point %v %v, want {-3 7}", p, err)
+	}
+	var names []string
+	if err := loaded.GetGob("names", &names); err != nil || !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("got names %v %v, want [a b]", names, err)
+	}
+	if err := loaded.Get("missing", &p); err != ErrNoSection {
+		t.Errorf("got %v for a missing section, want ErrNoSection", err)
+	}
+	if err := loaded.GetGob("missing", &names); err != ErrNoSection {
+		t.Errorf("got %v for a missing gob section, want ErrNoSection", err)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	var good bytes.Buffer
+	schema := NewSchema(2)
+	save := schema.New()
+	save.PutBytes("a", []byte("data"))
+	schema.Write(&good, save)
+
+	badMagic := append([]byte(nil), good.Bytes()...)
+	badMagic[0] = 'X'
+	badChecksum := append([]byte(nil), good.Bytes()...)
+	badChecksum[12]++
+	badCompression := append([]byte(nil), good.Bytes()[:16]...)
+	badCompression = append(badCompression, "this is not gzip data"...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, io.EOF},
+		{"short header", good.Bytes()[:10], io.ErrUnexpectedEOF},
+		{"magic", badMagic, ErrMagic},
+		{"checksum", badChecksum, ErrChecksum},
+		{"compression", badCompression, gzip.ErrHeader},
+		{"truncated compression", good.Bytes()[:good.Len()-10], io.ErrUnexpectedEOF},
+		{"no count", validSave(2, []byte{1, 0}), io.ErrUnexpectedEOF},
+		{"missing section", validSave(2, payloadOf(2, "a", "data")), io.ErrUnexpectedEOF},
+		{"missing data", validSave(2, payloadOf(1, "a")), io.ErrUnexpectedEOF},
+		{"truncated data", validSave(2, payloadOf(1, "a", "data")[:13]), io.ErrUnexpectedEOF},
+		{"newer version", validSave(3, payloadOf(0)), ErrVersion},
+		{"no migration", validSave(1, payloadOf(0)), ErrMigration},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := schema.Read(bytes.NewReader(test.data))
+			if err != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestReadTooLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compresses MaxPayloadSize bytes")
+	}
+	tests := []struct {
+		name string
+		size int64
+		want error
+	}{
+		// A payload of zeros is a save with no sections followed by padding, which Read ignores.
+		{"at the limit", MaxPayloadSize, nil},
+		{"over the limit", MaxPayloadSize + 1, ErrTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			buf.Write(magic[:])
+			binary.Write(&buf, binary.LittleEndian, uint32(1))
+			// The checksum is filled in once the payload has been written.
+			binary.Write(&buf, binary.LittleEndian, uint32(0))
+			gz, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+			crc := crc32.NewIEEE()
+			if _, err := io.Copy(io.MultiWriter(gz, crc), io.LimitReader(zeros{}, test.size)); err != nil {
+				t.Fatal(err)
+			}
+			gz.Close()
+			data := buf.Bytes()
+			binary.LittleEndian.PutUint32(data[12:], crc.Sum32())
+
+			_, err := NewSchema(1).Read(bytes.NewReader(data))
+			if err != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+// zeros is an endless reader of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestMigrate(t *testing.T) {
+	// newSchema returns a version 4 schema whose migrations rename, add, and transform sections, failing on version 3 if fail is set.
+	newSchema := func(fail bool) *Schema {
+		schema := NewSchema(4)
+		schema.AddMigration(1, func(save *Save) error {
+			data, _ := save.Bytes("hp")
+			save.Delete("hp")
+			save.PutBytes("health", data)
+			return nil
+		})
+		schema.AddMigration(2, func(save *Save) error {
+			save.PutBytes("mana", []byte{0})
+			return nil
+		})
+		schema.AddMigration(3, func(save *Save) error {
+			if fail {
+				return errors.New("migration failed")
+			}
+			data, _ := save.Bytes("health")
+			save.PutBytes("health", append(data, '!'))
+			return nil
+		})
+		return schema
+	}
+
+	tests := []struct {
+		name     string
+		version  uint32
+		sections map[string][]byte
+		fail     bool
+		want     map[string][]byte
+		err      bool
+	}{
+		{"from 1", 1, map[string][]byte{"hp": []byte("10")}, false, map[string][]byte{"health": []byte("10!"), "mana": {0}}, false},
+		{"from 2", 2, map[string][]byte{"health": []byte("7")}, false, map[string][]byte{"health": []byte("7!"), "mana": {0}}, false},
+		{"from 3", 3, map[string][]byte{"health": []byte("5"), "mana": {9}}, false, map[string][]byte{"health": []byte("5!"), "mana": {9}}, false},
+		{"current", 4, map[string][]byte{"health": []byte("3")}, false, map[string][]byte{"health": []byte("3")}, false},
+		{"failing", 1, map[string][]byte{"hp": []byte("10")}, true, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema := newSchema(test.fail)
+			save := &Save{Version: test.version}
+			for name, data := range test.sections {
+				save.PutBytes(name, data)
+			}
+			var buf bytes.Buffer
+			if err := schema.Write(&buf, save); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := schema.Read(&buf)
+			if (err != nil) != test.err {
+				t.Fatalf("got error %v, want error %v", err, test.err)
+			}
+			if test.err {
+				return
+			}
+			if loaded.Version != 4 {
+				t.Errorf("got version %d, want 4", loaded.Version)
+			}
+			if !reflect.DeepEqual(loaded.Sections, test.want) {
+				t.Errorf("got %q, want %q", loaded.Sections, test.want)
+			}
+		})
+	}
+}
+
+// tempDir returns a new temporary directory and a function that removes it.
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "goro-save")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// exitedPID returns the PID of a process that has exited.
+func exitedPID(t *testing.T) int {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestLockFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents *string
+		want     error
+	}{
+		{"unlocked", nil, nil},
+		{"locked by this process", strPtr(strconv.Itoa(os.Getpid()) + "\n"), ErrLocked},
+		{"locked by an exited process", strPtr(strconv.Itoa(exitedPID(t)) + "\n"), nil},
+		{"unreadable lock", strPtr("garbage"), ErrLocked},
+		{"empty lock", strPtr(""), ErrLocked},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, remove := tempDir(t)
+			defer remove()
+			path := filepath.Join(dir, "game.sav")
+			if test.contents != nil {
+				if err := ioutil.WriteFile(path+".lock", []byte(*test.contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			lock, err := LockFile(path)
+			if err != test.want {
+				t.Fatalf("got %v, want %v", err, test.want)
+			}
+			if err != nil {
+				return
+			}
+			data, _ := ioutil.ReadFile(path + ".lock")
+			if want := strconv.Itoa(os.Getpid()) + "\n"; string(data) != want {
+				t.Errorf("lock holds %q, want %q", data, want)
+			}
+			if _, err := LockFile(path); err != ErrLocked {
+				t.Errorf("got %v locking twice, want ErrLocked", err)
+			}
+			if err := lock.Unlock(); err != nil {
+				t.Fatal(err)
+			}
+			lock, err = LockFile(path)
+			if err != nil {
+				t.Fatalf("got %v relocking, want nil", err)
+			}
+			lock.Unlock()
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestSaveFile(t *testing.T) {
+	for _, deleteOnLoad := range []bool{false, true} {
+		t.Run("DeleteOnLoad="+strconv.FormatBool(deleteOnLoad), func(t *testing.T) {
+			dir, remove := tempDir(t)
+			defer remove()
+			path := filepath.Join(dir, "game.sav")
+			schema := NewSchema(1)
+			schema.DeleteOnLoad = deleteOnLoad
+			save := schema.New()
+			save.PutBytes("a", []byte("first"))
+			if err := schema.SaveFile(path, save); err != nil {
+				t.Fatal(err)
+			}
+			save.PutBytes("a", []byte("second"))
+			if err := schema.SaveFile(path, save); err != nil {
+				t.Fatal(err)
+			}
+			files, _ := ioutil.ReadDir(dir)
+			if len(files) != 1 {
+				t.Errorf("got %d files after saving, want 1", len(files))
+			}
+
+			loaded, err := schema.LoadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := loaded.Bytes("a"); string(data) != "second" {
+				t.Errorf("got %q, want %q", data, "second")
+			}
+			_, err = os.Stat(path)
+			if exists := err == nil; exists == deleteOnLoad {
+				t.Errorf("file exists %v after loading, want %v", exists, !deleteOnLoad)
+			}
+		})
+	}
+}
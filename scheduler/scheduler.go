@@ -0,0 +1,344 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package scheduler provides a deterministic turn scheduler supporting energy-based speed systems, actors scheduled at fixed delays, and one-shot timers.
+package scheduler
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/gob"
+	"errors"
+)
+
+// DefaultThreshold is the energy an actor needs to take a turn.
+const DefaultThreshold = 100
+
+// Errors returned by the Scheduler.
+var (
+	ErrNoActor = errors.New("actor does not exist")
+	ErrSpeed   = errors.New("speed must be greater than 0")
+)
+
+// Turn is returned by Next. Either Actor is set to the actor whose turn it is, or Timer is set to the timer that has fired.
+type Turn struct {
+	Time  uint64
+	Actor uint64
+	Timer *Timer
+}
+
+// Timer is a one-shot event created by After. Kind, Target, and Data are for the caller's use, such as "poison", the poisoned actor, and the damage.
+type Timer struct {
+	ID     uint64
+	Kind   string
+	Target uint64
+	Data   []byte
+}
+
+// actor is an actor's speed and energy. Actors with a speed of 0 are scheduled only with Schedule.
+type actor struct {
+	Speed    int
+	Energy   int
+	LastTime uint64
+	Queued   bool
+}
+
+// entry is a queued actor turn or timer. Entries are ordered by time, then by a random tie-breaker, then by insertion order.
+type entry struct {
+	Time  uint64
+	Tie   uint64
+	Seq   uint64
+	Actor uint64
+	Timer *Timer
+}
+
+// Scheduler orders the turns of actors and the firing of timers. Time is measured in ticks. Ties between entries due at the same time are broken using a random source seeded by NewScheduler, so that a given seed always produces the same order.
+type Scheduler struct {
+	time      uint64
+	threshold int
+	seq       uint64
+	timerID   uint64
+	random    uint64
+	actors    map[uint64]*actor
+	queue     entryHeap
+}
+
+// NewScheduler returns a Scheduler at time 0 whose ties are broken using seed.
+func NewScheduler(seed int64) *Scheduler {
+	return &Scheduler{
+		threshold: DefaultThreshold,
+		random:    uint64(seed),
+		actors:    make(map[uint64]*actor),
+	}
+}
+
+// Time returns the current time.
+func (s *Scheduler) Time() uint64 {
+	return s.time
+}
+
+// SetThreshold sets the energy actors need to take a turn.
+func (s *Scheduler) SetThreshold(threshold int) {
+	s.threshold = threshold
+}
+
+// AddActor adds an energy-based actor that gains speed energy each tick and takes a turn whenever it has enough. The actor must call Done after each of its turns.
+func (s *Scheduler) AddActor(id uint64, speed int) error {
+	if speed <= 0 {
+		return ErrSpeed
+	}
+	s.RemoveActor(id)
+	s.actors[id] = &actor{Speed: speed, LastTime: s.time}
+	s.queueActor(id)
+	return nil
+}
+
+// SetSpeed changes an energy-based actor's speed. Energy gained so far is kept, and the actor's queued turn is moved to when it will have enough energy at the new speed.
+func (s *Scheduler) SetSpeed(id uint64, speed int) error {
+	a, ok := s.actors[id]
+	if !ok {
+		return ErrNoActor
+	}
+	if speed <= 0 {
+		return ErrSpeed
+	}
+	if a.Speed <= 0 {
+		// Actors scheduled with Schedule keep their queued turn, and start gaining energy now.
+		a.Speed = speed
+		a.LastTime = s.time
+		return nil
+	}
+	a.Energy += a.Speed * int(s.time-a.LastTime)
+	a.LastTime = s.time
+	a.Speed = speed
+	if a.Queued {
+		s.unqueue(id)
+		a.Queued = false
+		s.queueActor(id)
+	}
+	return nil
+}
+
+// Energy returns an actor's energy as of its last turn.
+func (s *Scheduler) Energy(id uint64) int {
+	if a, ok := s.actors[id]; ok {
+		return a.Energy
+	}
+	return 0
+}
+
+// RemoveActor removes the actor and its queued turn.
+func (s *Scheduler) RemoveActor(id uint64) {
+	delete(s.actors, id)
+	s.unqueue(id)
+}
+
+// unqueue removes any queued turns of the actor.
+func (s *Scheduler) unqueue(id uint64) {
+	queue := s.queue[:0]
+	for _, e := range s.queue {
+		if e.Timer == nil && e.Actor == id {
+			continue
+		}
+		queue = append(queue, e)
+	}
+	s.queue = queue
+	heap.Init(&s.queue)
+}
+
+// Schedule queues a turn for the actor after delay ticks. It does nothing if the actor already has a turn queued. Actors added with Schedule rather than AddActor have no energy, and must be scheduled again after each turn.
+func (s *Scheduler) Schedule(id uint64, delay uint64) {
+	if _, ok := s.actors[id]; !ok {
+		s.actors[id] = &actor{LastTime: s.time}
+	}
+	if s.actors[id].Queued {
+		return
+	}
+	s.actors[id].Queued = true
+	s.push(entry{Time: s.time + delay, Actor: id})
+}
+
+// Done ends an energy-based actor's turn, spending cost energy, and queues its next turn.
+func (s *Scheduler) Done(id uint64, cost int) error {
+	a, ok := s.actors[id]
+	if !ok || a.Speed <= 0 {
+		return ErrNoActor
+	}
+	a.Energy -= cost
+	s.queueActor(id)
+	return nil
+}
+
+// queueActor queues an energy-based actor's turn for when it will have enough energy.
+func (s *Scheduler) queueActor(id uint64) {
+	a := s.actors[id]
+	if a.Queued {
+		return
+	}
+	var delay uint64
+	if a.Energy < s.threshold {
+		delay = uint64((s.threshold - a.Energy + a.Speed - 1) / a.Speed)
+	}
+	a.Queued = true
+	s.push(entry{Time: s.time + delay, Actor: id})
+}
+
+// After creates a timer that fires after delay ticks.
+func (s *Scheduler) After(delay uint64, kind string, target uint64, data []byte) *Timer {
+	s.timerID++
+	timer := &Timer{ID: s.timerID, Kind: kind, Target: target, Data: data}
+	s.push(entry{Time: s.time + delay, Timer: timer})
+	return timer
+}
+
+// Cancel stops the timer with the given ID from firing, returning whether it was pending.
+func (s *Scheduler) Cancel(timerID uint64) bool {
+	for i, e := range s.queue {
+		if e.Timer != nil && e.Timer.ID == timerID {
+			heap.Remove(&s.queue, i)
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the number of queued turns and timers.
+func (s *Scheduler) Pending() int {
+	return len(s.queue)
+}
+
+// Next advances time to the earliest queued turn or timer and returns it, or returns false if nothing is queued.
+func (s *Scheduler) Next() (Turn, bool) {
+	if len(s.queue) == 0 {
+		return Turn{}, false
+	}
+	e := heap.Pop(&s.queue).(entry)
+	s.time = e.Time
+	if e.Timer != nil {
+		return Turn{Time: s.time, Timer: e.Timer}, true
+	}
+	if a, ok := s.actors[e.Actor]; ok {
+		a.Queued = false
+		a.Energy += a.Speed * int(s.time-a.LastTime)
+		a.LastTime = s.time
+	}
+	return Turn{Time: s.time, Actor: e.Actor}, true
+}
+
+// RunUntil handles turns with handle until it is the given actor's turn, which it returns without handling. This lets a game block for the player's input between calls while other actors take their turns. It returns false if nothing is left in the queue.
+func (s *Scheduler) RunUntil(id uint64, handle func(turn Turn)) (Turn, bool) {
+	for {
+		turn, ok := s.Next()
+		if !ok {
+			return Turn{}, false
+		}
+		if turn.Timer == nil && turn.Actor == id {
+			return turn, true
+		}
+		handle(turn)
+	}
+}
+
+// push queues an entry with the next tie-breaker and sequence number.
+func (s *Scheduler) push(e entry) {
+	s.seq++
+	e.Seq = s.seq
+	e.Tie = s.nextRandom()
+	heap.Push(&s.queue, e)
+}
+
+// nextRandom returns the next value of the splitmix64 generator used for tie-breaking. Its state is a single number, which keeps it easy to serialize.
+func (s *Scheduler) nextRandom() uint64 {
+	s.random += 0x9E3779B97F4A7C15
+	z := s.random
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// schedulerState is the serialized form of a Scheduler.
+type schedulerState struct {
+	Time      uint64
+	Threshold int
+	Seq       uint64
+	TimerID   uint64
+	Random    uint64
+	Actors    map[uint64]*actor
+	Queue     []entry
+}
+
+// MarshalBinary encodes the Scheduler, including its queued turns and timers.
+func (s *Scheduler) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(schedulerState{
+		Time:      s.time,
+		Threshold: s.threshold,
+		Seq:       s.seq,
+		TimerID:   s.timerID,
+		Random:    s.random,
+		Actors:    s.actors,
+		Queue:     s.queue,
+	})
+	return buf.Bytes(), err
+}
+
+// UnmarshalBinary decodes a Scheduler encoded by MarshalBinary.
+func (s *Scheduler) UnmarshalBinary(data []byte) error {
+	var state schedulerState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	s.time = state.Time
+	s.threshold = state.Threshold
+	s.seq = state.Seq
+	s.timerID = state.TimerID
+	s.random = state.Random
+	s.actors = state.Actors
+	if s.actors == nil {
+		s.actors = make(map[uint64]*actor)
+	}
+	s.queue = state.Queue
+	heap.Init(&s.queue)
+	return nil
+}
+
+// entryHeap is a min-heap of entries.
+type entryHeap []entry
+
+func (h entryHeap) Len() int { return len(h) }
+
+func (h entryHeap) Less(i, j int) bool {
+	if h[i].Time != h[j].Time {
+		return h[i].Time < h[j].Time
+	}
+	if h[i].Tie != h[j].Tie {
+		return h[i].Tie < h[j].Tie
+	}
+	return h[i].Seq < h[j].Seq
+}
+
+func (h entryHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *entryHeap) Push(x interface{}) { *h = append(*h, x.(entry)) }
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
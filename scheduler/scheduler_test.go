@@ -0,0 +1,172 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestScheduler returns a Scheduler with actors of equal and differing speeds, so that ties occur, and a timer.
+func newTestScheduler(seed int64) *Scheduler {
+	s := NewScheduler(seed)
+	s.AddActor(1, 10)
+	s.AddActor(2, 10)
+	s.AddActor(3, 10)
+	s.AddActor(4, 25)
+	s.After(35, "poison", 2, nil)
+	return s
+}
+
+// runTurns handles n turns, with each actor spending a full turn's energy, and returns them.
+func runTurns(s *Scheduler, n int) []Turn {
+	var turns []Turn
+	for i := 0; i < n; i++ {
+		turn, ok := s.Next()
+		if !ok {
+			break
+		}
+		if turn.Timer == nil {
+			s.Done(turn.Actor, DefaultThreshold)
+		}
+		turns = append(turns, turn)
+	}
+	return turns
+}
+
+func TestSchedulerDeterministic(t *testing.T) {
+	first := runTurns(newTestScheduler(42), 50)
+	second := runTurns(newTestScheduler(42), 50)
+	if len(first) != 50 {
+		t.Fatalf("got %d turns, want 50", len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("the same seed gave different orders:\n%v\n%v", first, second)
+	}
+}
+
+func TestSchedulerMarshal(t *testing.T) {
+	s := newTestScheduler(7)
+	runTurns(s, 10)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := runTurns(s, 40)
+
+	restored := &Scheduler{}
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := runTurns(restored, 40); !reflect.DeepEqual(got, want) {
+		t.Fatalf("restored scheduler diverged:\n%v\n%v", got, want)
+	}
+}
+
+func TestSchedulerSetSpeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(s *Scheduler)
+		wantTime   uint64
+		wantEnergy int
+	}{
+		{
+			// Speed 10 needs 10 ticks per turn. Doubling it at time 10 halves the wait for the next turn.
+			name: "energy actor is requeued",
+			setup: func(s *Scheduler) {
+				s.AddActor(1, 10)
+				runTurns(s, 1)
+				s.SetSpeed(1, 50)
+			},
+			wantTime:   12,
+			wantEnergy: 100,
+		},
+		{
+			// An actor created by Schedule only starts gaining energy once it has a speed.
+			name: "scheduled actor gains no backlog",
+			setup: func(s *Scheduler) {
+				s.Schedule(1, 0)
+				s.After(50, "wait", 0, nil)
+				s.Next()
+				s.Next()
+				s.SetSpeed(1, 10)
+				s.Done(1, 0)
+			},
+			wantTime:   60,
+			wantEnergy: 100,
+		},
+	}
+	for _, test := range tests {
+		s := NewScheduler(1)
+		test.setup(s)
+		turn, ok := s.Next()
+		if !ok || turn.Actor != 1 {
+			t.Fatalf("%s: got turn %v, want actor 1", test.name, turn)
+		}
+		if turn.Time != test.wantTime || s.Energy(1) != test.wantEnergy {
+			t.Fatalf("%s: got time %d and energy %d, want %d and %d", test.name, turn.Time, s.Energy(1), test.wantTime, test.wantEnergy)
+		}
+	}
+	if err := NewScheduler(1).SetSpeed(1, 10); err != ErrNoActor {
+		t.Fatalf("got %v for a missing actor, want ErrNoActor", err)
+	}
+	s := NewScheduler(1)
+	s.AddActor(1, 10)
+	if err := s.SetSpeed(1, 0); err != ErrSpeed {
+		t.Fatalf("got %v for a speed of 0, want ErrSpeed", err)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := NewScheduler(1)
+	poison := s.After(5, "poison", 1, nil)
+	burn := s.After(10, "burn", 1, nil)
+	if !s.Cancel(poison.ID) {
+		t.Fatal("canceling a pending timer returned false")
+	}
+	if s.Cancel(poison.ID) {
+		t.Fatal("canceling a canceled timer returned true")
+	}
+	turn, ok := s.Next()
+	if !ok || turn.Timer != burn || turn.Time != 10 {
+		t.Fatalf("got turn %v, want the burn timer at 10", turn)
+	}
+	if _, ok := s.Next(); ok {
+		t.Fatal("a canceled timer fired")
+	}
+}
+
+func TestSchedulerQueued(t *testing.T) {
+	s := NewScheduler(1)
+	s.Schedule(1, 5)
+	s.Schedule(1, 3)
+	if s.Pending() != 1 {
+		t.Fatalf("got %d pending turns, want 1", s.Pending())
+	}
+	for id := uint64(2); id < 10; id++ {
+		s.Schedule(id, id%3)
+	}
+	s.RemoveActor(1)
+	s.RemoveActor(5)
+	for turn, ok := s.Next(); ok; turn, ok = s.Next() {
+		if turn.Actor == 1 || turn.Actor == 5 {
+			t.Fatalf("removed actor %d took a turn", turn.Actor)
+		}
+	}
+}
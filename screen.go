@@ -19,6 +19,7 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package goro
 
 import (
+	"context"
 	"errors"
 	"sync"
 
@@ -27,11 +28,22 @@ import (
 
 // Screen is a virtual Rows x Columns buffer used for drawing runes to.
 type Screen struct {
+	X, Y             int // Position within the backend, in cells.
+	z                int
 	ScrollX, ScrollY int
 	Columns, Rows    int
 	cells            [][]Cell
 	active           bool
 	eventChan        chan Event
+	eventPolicy      OverflowPolicy
+	replaying        bool
+	eventMutex       sync.Mutex
+	eventSwap        chan struct{}
+	blockedPushes    sync.WaitGroup
+	tick             tickState
+	tickMutex        sync.Mutex
+	animations       []*animationEntry
+	animationsMutex  sync.Mutex
 	UseKeys          bool
 	UseMouse         bool
 	AutoSize         bool
@@ -40,20 +52,30 @@ type Screen struct {
 	Redraw           bool
 	cellsMutex       sync.Mutex
 	backend          Backend
+	ctx              context.Context
+	frameRecorders   []FrameRecorder
+	recordMutex      sync.Mutex
+	theme            *Theme
+	clips            []Rect
+	pendingScrolls   []scrollOp
+	scrolls          []scrollOp
+
+	eventRecorders      []EventRecorder
+	eventRecordersMutex sync.Mutex
 }
 
-// Init initializes the Screen's data structures and default values.
-func (screen *Screen) Init() (err error) {
-	screen.eventChan = make(chan Event, 10)
+// Init initializes the Screen's data structures and default values for use with the provided backend.
+func (screen *Screen) Init(backend Backend) (err error) {
+	screen.eventChan = make(chan Event, DefaultEventBufferSize)
 	// Assign our Screen size to either the backend's columns and rows if it uses Cells for units, otherwise use a standard 80x24 size.
-	if globalBackend != nil {
-		if globalBackend.Units() == UnitCells {
-			screen.Columns, screen.Rows = globalBackend.Size()
+	if backend != nil {
+		if backend.Units() == UnitCells {
+			screen.Columns, screen.Rows = backend.Size()
 		} else {
 			screen.Columns, screen.Rows = 80, 24
 		}
 	}
-	screen.backend = globalBackend
+	screen.backend = backend
 	screen.active = true
 	screen.UseKeys = true
 	screen.AutoSize = true
@@ -67,7 +89,7 @@ func NewScreen(width, height int) (screen *Screen, err error) {
 		Columns:   width,
 		Rows:      height,
 		active:    true,
-		eventChan: make(chan Event, 10),
+		eventChan: make(chan Event, DefaultEventBufferSize),
 		backend:   &BackendVirtual{},
 	}
 
@@ -76,9 +98,22 @@ func NewScreen(width, height int) (screen *Screen, err error) {
 	return
 }
 
-// WaitEvent returns an Event from the Screen's event channel.
+// WaitEvent returns an Event from the Screen's event channel. EventQuit is returned once the Screen's context is done.
 func (screen *Screen) WaitEvent() Event {
-	return <-screen.eventChan
+	select {
+	case event := <-screen.Events():
+		return screen.Deliver(event)
+	case <-screen.Context().Done():
+		return EventQuit{}
+	}
+}
+
+// Context returns the Screen's context, which is canceled when its App quits or, for network backends, when its connection closes.
+func (screen *Screen) Context() context.Context {
+	if screen.ctx == nil {
+		return context.Background()
+	}
+	return screen.ctx
 }
 
 // Close sets the Screen as inactive thereby allowing the backend to clean it up.
@@ -124,19 +159,22 @@ func (screen *Screen) checkBounds(x, y int) error {
 	return nil
 }
 
-// DrawScreen draws the provided screen onto the current screen.
+// DrawScreen draws the provided screen onto the current screen, copying each cell's rune, style, and glyphs. Cells beyond the edges of the subscreen leave the current screen untouched.
 func (screen *Screen) DrawScreen(destX, destY, destWidth, destHeight, sourceX, sourceY int, subscreen *Screen) error {
-	for y := 0; y < destHeight; y++ {
-		for x := 0; x < destWidth; x++ {
-			cell, err := subscreen.getCell(sourceX+x, sourceY+y)
-			if err == nil {
-				screen.DrawRune(destX+x, destY+y, cell.PendingRune, cell.PendingStyle)
-			} else {
-				screen.DrawRune(destX+x, destY+y, ' ', Style{})
-			}
-		}
-	}
-	return nil
+	return screen.DrawScreenOptions(destX, destY, destWidth, destHeight, sourceX, sourceY, subscreen, BlitOptions{})
+}
+
+// DrawScreenOptions draws the provided screen onto the current screen as DrawScreen does, compositing it according to options. The destination width and height are after any rotation.
+func (screen *Screen) DrawScreenOptions(destX, destY, destWidth, destHeight, sourceX, sourceY int, subscreen *Screen, options BlitOptions) error {
+	width, height := options.size(destWidth, destHeight)
+	return screen.Blit(destX, destY, subscreen, Rect{sourceX, sourceY, width, height}, options)
+}
+
+// pendingCell returns a copy of the Cell at the given coordinates, holding the cells lock.
+func (screen *Screen) pendingCell(x, y int) (Cell, error) {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	return screen.getCell(x, y)
 }
 
 // getCell returns the Cell at the given coordinates.
@@ -151,27 +189,43 @@ func (screen *Screen) getCell(x, y int) (cell Cell, err error) {
 func (screen *Screen) DrawRune(x int, y int, r rune, s Style) error {
 	screen.cellsMutex.Lock()
 	defer screen.cellsMutex.Unlock()
+	return screen.drawRune(x, y, r, s)
+}
+
+// drawRune draws a given rune at the position of x and y with a given style. The cells lock must be held.
+func (screen *Screen) drawRune(x int, y int, r rune, s Style) error {
 	if err := screen.checkBounds(x, y); err != nil {
 		return err
 	}
-	if screen.cells[y][x].PendingRune == r && screen.cells[y][x].PendingStyle == s {
+	if !screen.inClip(x, y) {
+		return nil
+	}
+	if screen.cells[y][x].PendingRune == r && screen.cells[y][x].PendingStyle == s && screen.cells[y][x].PendingRole == "" {
 		return nil
 	}
 	screen.cells[y][x].PendingRune = r
 	screen.cells[y][x].PendingStyle = s
+	screen.cells[y][x].PendingRole = ""
 	screen.cells[y][x].Dirty = true
 	return nil
 }
 
 // DrawString draws a string at the position of x and y with a given style, iterating in the x direction as it goes.
 func (screen *Screen) DrawString(x int, y int, str string, s Style) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	return screen.drawString(x, y, str, s)
+}
+
+// drawString draws a string at the position of x and y with a given style. The cells lock must be held.
+func (screen *Screen) drawString(x int, y int, str string, s Style) error {
 	origX := x
 	for _, r := range str {
 		if r == '\n' {
 			x = origX
 			y++
 		} else {
-			if err := screen.DrawRune(x, y, r, s); err != nil {
+			if err := screen.drawRune(x, y, r, s); err != nil {
 				return err
 			}
 			x++
@@ -184,13 +238,22 @@ func (screen *Screen) DrawString(x int, y int, str string, s Style) error {
 func (screen *Screen) SetForeground(x int, y int, c Color) error {
 	screen.cellsMutex.Lock()
 	defer screen.cellsMutex.Unlock()
+	return screen.setForeground(x, y, c)
+}
+
+// setForeground sets the foreground at the given location. The cells lock must be held.
+func (screen *Screen) setForeground(x int, y int, c Color) error {
 	if err := screen.checkBounds(x, y); err != nil {
 		return err
 	}
+	if !screen.inClip(x, y) {
+		return nil
+	}
 	if screen.cells[y][x].PendingStyle.Foreground == c {
 		return nil
 	}
 	screen.cells[y][x].PendingStyle.Foreground = c
+	screen.cells[y][x].PendingRole = ""
 	screen.cells[y][x].Dirty = true
 	return nil
 }
@@ -199,13 +262,22 @@ func (screen *Screen) SetForeground(x int, y int, c Color) error {
 func (screen *Screen) SetBackground(x int, y int, c Color) error {
 	screen.cellsMutex.Lock()
 	defer screen.cellsMutex.Unlock()
+	return screen.setBackground(x, y, c)
+}
+
+// setBackground sets the background at the given location. The cells lock must be held.
+func (screen *Screen) setBackground(x int, y int, c Color) error {
 	if err := screen.checkBounds(x, y); err != nil {
 		return err
 	}
+	if !screen.inClip(x, y) {
+		return nil
+	}
 	if screen.cells[y][x].PendingStyle.Background == c {
 		return nil
 	}
 	screen.cells[y][x].PendingStyle.Background = c
+	screen.cells[y][x].PendingRole = ""
 	screen.cells[y][x].Dirty = true
 	return nil
 }
@@ -214,13 +286,22 @@ func (screen *Screen) SetBackground(x int, y int, c Color) error {
 func (screen *Screen) SetStyle(x int, y int, s Style) error {
 	screen.cellsMutex.Lock()
 	defer screen.cellsMutex.Unlock()
+	return screen.setStyle(x, y, s)
+}
+
+// setStyle sets the style at the given location. The cells lock must be held.
+func (screen *Screen) setStyle(x int, y int, s Style) error {
 	if err := screen.checkBounds(x, y); err != nil {
 		return err
 	}
-	if screen.cells[y][x].PendingStyle == s {
+	if !screen.inClip(x, y) {
+		return nil
+	}
+	if screen.cells[y][x].PendingStyle == s && screen.cells[y][x].PendingRole == "" {
 		return nil
 	}
 	screen.cells[y][x].PendingStyle = s
+	screen.cells[y][x].PendingRole = ""
 	screen.cells[y][x].Dirty = true
 	return nil
 }
@@ -229,9 +310,17 @@ func (screen *Screen) SetStyle(x int, y int, s Style) error {
 func (screen *Screen) SetRune(x int, y int, r rune) error {
 	screen.cellsMutex.Lock()
 	defer screen.cellsMutex.Unlock()
+	return screen.setRune(x, y, r)
+}
+
+// setRune sets the rune at the given location. The cells lock must be held.
+func (screen *Screen) setRune(x int, y int, r rune) error {
 	if err := screen.checkBounds(x, y); err != nil {
 		return err
 	}
+	if !screen.inClip(x, y) {
+		return nil
+	}
 	if screen.cells[y][x].PendingRune == r {
 		return nil
 	}
@@ -244,9 +333,17 @@ func (screen *Screen) SetRune(x int, y int, r rune) error {
 func (screen *Screen) SetGlyphsID(x int, y int, id glyphs.ID) error {
 	screen.cellsMutex.Lock()
 	defer screen.cellsMutex.Unlock()
+	return screen.setGlyphsID(x, y, id)
+}
+
+// setGlyphsID sets the glyphs at a given location. The cells lock must be held.
+func (screen *Screen) setGlyphsID(x int, y int, id glyphs.ID) error {
 	if err := screen.checkBounds(x, y); err != nil {
 		return err
 	}
+	if !screen.inClip(x, y) {
+		return nil
+	}
 	if screen.cells[y][x].PendingGlyphs == id {
 		return nil
 	}
@@ -255,34 +352,105 @@ func (screen *Screen) SetGlyphsID(x int, y int, id glyphs.ID) error {
 	return nil
 }
 
+// SetOffset sets how far the rune at the given location is shifted from the center of its cell, as a fraction of a cell. Only backends that can draw between cells, such as ebiten, show offsets.
+func (screen *Screen) SetOffset(x int, y int, offsetX, offsetY float64) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	return screen.setOffset(x, y, offsetX, offsetY)
+}
+
+// setOffset sets the offset of the rune at the given location. The cells lock must be held.
+func (screen *Screen) setOffset(x int, y int, offsetX, offsetY float64) error {
+	if err := screen.checkBounds(x, y); err != nil {
+		return err
+	}
+	if !screen.inClip(x, y) {
+		return nil
+	}
+	if screen.cells[y][x].PendingOffsetX == offsetX && screen.cells[y][x].PendingOffsetY == offsetY {
+		return nil
+	}
+	screen.cells[y][x].PendingOffsetX = offsetX
+	screen.cells[y][x].PendingOffsetY = offsetY
+	screen.cells[y][x].Dirty = true
+	return nil
+}
+
 // Clear clears the underlying screen.
 func (screen *Screen) Clear() {
+	screen.cellsMutex.Lock()
 	for y := 0; y < len(screen.cells); y++ {
 		for x := 0; x < len(screen.cells[y]); x++ {
-			screen.DrawRune(x, y, ' ', Style{})
+			screen.drawRune(x, y, ' ', Style{})
 		}
 	}
+	screen.cellsMutex.Unlock()
 	screen.Flush()
 }
 
 // Flush forcibly causes the screen to commit any pending changes via a Draw* call and render to the backend.
 func (screen *Screen) Flush() {
 	screen.cellsMutex.Lock()
+	screen.commitScrolls()
+	recording := len(screen.frameRecorders) > 0
+	var frame Frame
+	if recording {
+		frame = screen.newFrame(false)
+	}
 	for y := 0; y < len(screen.cells); y++ {
 		for x := 0; x < len(screen.cells[y]); x++ {
 			if screen.cells[y][x].Dirty {
 				screen.cells[y][x].Rune = screen.cells[y][x].PendingRune
 				screen.cells[y][x].Style = screen.cells[y][x].PendingStyle
+				screen.cells[y][x].Role = screen.cells[y][x].PendingRole
 				screen.cells[y][x].Glyphs = screen.cells[y][x].PendingGlyphs
+				offset := screen.cells[y][x].OffsetX != 0 || screen.cells[y][x].OffsetY != 0
+				screen.cells[y][x].OffsetX = screen.cells[y][x].PendingOffsetX
+				screen.cells[y][x].OffsetY = screen.cells[y][x].PendingOffsetY
+				if offset || screen.cells[y][x].OffsetX != 0 || screen.cells[y][x].OffsetY != 0 {
+					// An offset rune may overlap neighboring cells, so they must be redrawn as well.
+					screen.redrawNeighbors(x, y)
+				}
 				screen.cells[y][x].Dirty = false
 				screen.cells[y][x].Redraw = true
+				if recording {
+					frame.Cells = append(frame.Cells, FrameCell{
+						X:      x,
+						Y:      y,
+						Rune:   screen.cells[y][x].Rune,
+						Style:  screen.cells[y][x].Style,
+						Glyphs: screen.cells[y][x].Glyphs,
+					})
+				}
 			}
 		}
 	}
 	screen.Redraw = true
-	defer screen.cellsMutex.Unlock()
 	// hmm. We're calling this here so we can force render the view.
 	screen.backend.Refresh()
+	if !recording {
+		screen.cellsMutex.Unlock()
+		return
+	}
+	// Recorders are called outside the cells lock so a slow one does not stall drawing. The record lock is taken first so frames arrive in order.
+	recorders := append([]FrameRecorder(nil), screen.frameRecorders...)
+	screen.recordMutex.Lock()
+	screen.cellsMutex.Unlock()
+	defer screen.recordMutex.Unlock()
+	for _, recorder := range recorders {
+		recorder.RecordFrame(frame)
+	}
+}
+
+// redrawNeighbors marks the cells around the given location to be redrawn. The cells lock must be held.
+func (screen *Screen) redrawNeighbors(x, y int) {
+	for ny := y - 1; ny <= y+1; ny++ {
+		for nx := x - 1; nx <= x+1; nx++ {
+			if screen.checkBounds(nx, ny) == nil {
+				screen.cells[ny][nx].Redraw = true
+			}
+		}
+	}
 }
 
 // ForceRedraw marks each cell of the screen to be redrawn.
@@ -320,6 +488,32 @@ func (screen *Screen) SetSize(c, r int) {
 	screen.Rows = r
 	screen.Sync()
 	screen.backend.SyncSize()
+	if screens := screen.backend.Screens(); screens != nil {
+		screens.damage()
+	}
+}
+
+// Position returns the screen's position within the backend, in cells.
+func (screen *Screen) Position() (int, int) {
+	return screen.X, screen.Y
+}
+
+// SetPosition moves the screen to the given position within the backend, in cells.
+func (screen *Screen) SetPosition(x, y int) {
+	screen.X, screen.Y = x, y
+	if screens := screen.backend.Screens(); screens != nil {
+		screens.damage()
+	}
+}
+
+// Screens returns the collection of Screens displayed by the screen's backend. It is used for creating additional Screens and managing their order and focus.
+func (screen *Screen) Screens() *Screens {
+	return screen.backend.Screens()
+}
+
+// contains returns whether the given backend cell is within the screen.
+func (screen *Screen) contains(x, y int) bool {
+	return x >= screen.X && x < screen.X+screen.Columns && y >= screen.Y && y < screen.Y+screen.Rows
 }
 
 // WindowSize returns the current backend's window size in its preferred units, if available.
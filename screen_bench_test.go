@@ -0,0 +1,137 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"testing"
+)
+
+// newBenchScreen returns an 80x24 Screen that is not displayed.
+func newBenchScreen(b *testing.B) *Screen {
+	screen := &Screen{}
+	if err := screen.Init(&BackendVirtual{}); err != nil {
+		b.Fatal(err)
+	}
+	screen.SetSize(80, 24)
+	return screen
+}
+
+// contend holds the Screen's lock while walking its cells, as a backend drawing goroutine does, until the returned function is called.
+func contend(screen *Screen) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			screen.cellsMutex.Lock()
+			for y := range screen.cells {
+				for x := range screen.cells[y] {
+					screen.cells[y][x].Redraw = false
+				}
+			}
+			screen.cellsMutex.Unlock()
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// frameRune returns a rune that differs between frames, so that no cell is skipped as unchanged.
+func frameRune(frame int) rune {
+	return rune('a' + frame%26)
+}
+
+func benchmarkDrawRune(b *testing.B, screen *Screen) {
+	s := Style{Foreground: ColorWhite, Background: ColorBlack}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := frameRune(i)
+		for y := 0; y < screen.Rows; y++ {
+			for x := 0; x < screen.Columns; x++ {
+				screen.DrawRune(x, y, r, s)
+			}
+		}
+	}
+}
+
+func benchmarkBatch(b *testing.B, screen *Screen) {
+	s := Style{Foreground: ColorWhite, Background: ColorBlack}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := frameRune(i)
+		screen.Batch(func(batch *Batch) {
+			for y := 0; y < screen.Rows; y++ {
+				for x := 0; x < screen.Columns; x++ {
+					batch.DrawRune(x, y, r, s)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDrawRune draws a full 80x24 frame one locked DrawRune at a time.
+func BenchmarkDrawRune(b *testing.B) {
+	benchmarkDrawRune(b, newBenchScreen(b))
+}
+
+// BenchmarkBatch draws a full 80x24 frame within a single Batch.
+func BenchmarkBatch(b *testing.B) {
+	benchmarkBatch(b, newBenchScreen(b))
+}
+
+// BenchmarkDrawRuneContended draws a full frame with DrawRune while another goroutine competes for the Screen's lock.
+func BenchmarkDrawRuneContended(b *testing.B) {
+	screen := newBenchScreen(b)
+	defer contend(screen)()
+	benchmarkDrawRune(b, screen)
+}
+
+// BenchmarkBatchContended draws a full frame within a Batch while another goroutine competes for the Screen's lock.
+func BenchmarkBatchContended(b *testing.B) {
+	screen := newBenchScreen(b)
+	defer contend(screen)()
+	benchmarkBatch(b, screen)
+}
+
+// BenchmarkFlush commits a fully changed 80x24 frame.
+func BenchmarkFlush(b *testing.B) {
+	screen := newBenchScreen(b)
+	s := Style{Foreground: ColorWhite, Background: ColorBlack}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		r := frameRune(i)
+		screen.Batch(func(batch *Batch) {
+			for y := 0; y < screen.Rows; y++ {
+				for x := 0; x < screen.Columns; x++ {
+					batch.DrawRune(x, y, r, s)
+				}
+			}
+		})
+		b.StartTimer()
+		screen.Flush()
+	}
+}
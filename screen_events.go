@@ -0,0 +1,254 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultEventBufferSize is the number of events a Screen buffers before its OverflowPolicy applies.
+const DefaultEventBufferSize = 10
+
+// OverflowPolicy controls what happens when an event is sent to a Screen whose event buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until there is room for the event or the Screen's context is done. This is the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered event to make room.
+	OverflowDropOldest
+	// OverflowDropNewest discards the new event.
+	OverflowDropNewest
+	// OverflowCoalesce merges buffered resize events and repeated mouse events, keeping only the latest of each, then discards the oldest event if there is still no room.
+	OverflowCoalesce
+)
+
+// SetEventBuffer sets the size of the Screen's event buffer and the policy used when it is full. Buffered events are kept, up to the new size. Channels previously returned by Events no longer receive events.
+func (screen *Screen) SetEventBuffer(size int, policy OverflowPolicy) {
+	if size < 1 {
+		size = 1
+	}
+	screen.eventMutex.Lock()
+	defer screen.eventMutex.Unlock()
+	// Wake pushes waiting on the old channel and wait for them to stop, so that none of them can send to it once it has been drained. They retry on the new channel.
+	if screen.eventSwap != nil {
+		close(screen.eventSwap)
+		screen.eventSwap = nil
+	}
+	screen.blockedPushes.Wait()
+	eventChan := make(chan Event, size)
+	for len(eventChan) < size {
+		select {
+		case event := <-screen.eventChan:
+			eventChan <- event
+			continue
+		default:
+		}
+		break
+	}
+	screen.eventChan = eventChan
+	screen.eventPolicy = policy
+}
+
+// Events returns the channel the Screen's events are delivered on, for use in a select alongside other channels such as timers. Each event received from it MUST be passed to Deliver, or it will not be recorded for replays and EventTicks will not step animations.
+func (screen *Screen) Events() <-chan Event {
+	screen.eventMutex.Lock()
+	defer screen.eventMutex.Unlock()
+	return screen.eventChan
+}
+
+// PollEvent returns the next buffered Event without waiting. It returns nil if there are none, or EventQuit if there are none and the Screen's context is done.
+func (screen *Screen) PollEvent() Event {
+	select {
+	case event := <-screen.Events():
+		return screen.Deliver(event)
+	default:
+	}
+	if screen.Context().Err() != nil {
+		return EventQuit{}
+	}
+	return nil
+}
+
+// WaitEventTimeout returns the next Event, waiting up to timeout for one to arrive. It returns nil if the timeout elapses, or EventQuit if the Screen's context is done.
+func (screen *Screen) WaitEventTimeout(timeout time.Duration) Event {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case event := <-screen.Events():
+		return screen.Deliver(event)
+	case <-screen.Context().Done():
+		return EventQuit{}
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WaitEventContext returns the next Event, waiting until one arrives or ctx is done, in which case ctx's error is returned. EventQuit is returned if the Screen's context is done.
+func (screen *Screen) WaitEventContext(ctx context.Context) (Event, error) {
+	select {
+	case event := <-screen.Events():
+		return screen.Deliver(event), nil
+	case <-screen.Context().Done():
+		return EventQuit{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// pushEvent sends an event to the Screen, applying the Screen's OverflowPolicy if its buffer is full. Live events are ignored while the Screen is replaying.
+func (screen *Screen) pushEvent(event Event) {
+	screen.eventMutex.Lock()
+	if screen.replaying {
+		screen.eventMutex.Unlock()
+		return
+	}
+	select {
+	case screen.eventChan <- event:
+		screen.eventMutex.Unlock()
+		return
+	default:
+	}
+	switch screen.eventPolicy {
+	case OverflowBlock:
+		screen.eventMutex.Unlock()
+		screen.pushBlocking(event)
+		return
+	case OverflowDropOldest:
+		screen.pushDroppingOldest(screen.eventChan, event)
+	case OverflowDropNewest:
+	case OverflowCoalesce:
+		screen.pushCoalescing(screen.eventChan, event)
+	}
+	screen.eventMutex.Unlock()
+}
+
+// pushBlocking sends an event to the Screen, waiting until there is room. If SetEventBuffer replaces the buffer while waiting, the event is sent to the new one. It returns false if the Screen's context is done first.
+func (screen *Screen) pushBlocking(event Event) bool {
+	for {
+		screen.eventMutex.Lock()
+		eventChan := screen.eventChan
+		select {
+		case eventChan <- event:
+			screen.eventMutex.Unlock()
+			return true
+		default:
+		}
+		if screen.eventSwap == nil {
+			screen.eventSwap = make(chan struct{})
+		}
+		swap := screen.eventSwap
+		screen.blockedPushes.Add(1)
+		screen.eventMutex.Unlock()
+
+		select {
+		case eventChan <- event:
+			screen.blockedPushes.Done()
+			return true
+		case <-screen.Context().Done():
+			screen.blockedPushes.Done()
+			return false
+		case <-swap:
+			screen.blockedPushes.Done()
+		}
+	}
+}
+
+// pushDroppingOldest discards buffered events until event fits. The event lock must be held.
+func (screen *Screen) pushDroppingOldest(eventChan chan Event, event Event) {
+	for {
+		select {
+		case eventChan <- event:
+			return
+		default:
+		}
+		select {
+		case <-eventChan:
+		default:
+		}
+	}
+}
+
+// pushCoalescing drains the buffered events, merges them with event, and buffers the result, dropping the oldest events if they still do not fit. The event lock must be held.
+func (screen *Screen) pushCoalescing(eventChan chan Event, event Event) {
+
+	var events []Event
+	for len(events) < cap(eventChan) {
+		select {
+		case e := <-eventChan:
+			events = append(events, e)
+			continue
+		default:
+		}
+		break
+	}
+	events = coalesceEvents(append(events, event))
+	if len(events) > cap(eventChan) {
+		events = events[len(events)-cap(eventChan):]
+	}
+	for _, e := range events {
+		select {
+		case eventChan <- e:
+		default:
+			// A blocked sender took the room; the remaining events are dropped as if they were the oldest.
+			return
+		}
+	}
+}
+
+// coalesceEvents returns events with each resize replaced by the latest resize, and each run of mouse events sharing a button and state replaced by the latest of the run.
+func coalesceEvents(events []Event) []Event {
+	lastResize := -1
+	for i, event := range events {
+		if _, ok := event.(EventResize); ok {
+			lastResize = i
+		}
+	}
+	result := events[:0]
+	for i, event := range events {
+		switch event := event.(type) {
+		case EventResize:
+			if i != lastResize {
+				continue
+			}
+		case EventMouse:
+			if len(result) > 0 {
+				if previous, ok := result[len(result)-1].(EventMouse); ok && previous.Button == event.Button && previous.State == event.State {
+					result[len(result)-1] = event
+					continue
+				}
+			}
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+// setReplaying sets whether the Screen ignores live events in favor of those sent by pushReplayed.
+func (screen *Screen) setReplaying(replaying bool) {
+	screen.eventMutex.Lock()
+	defer screen.eventMutex.Unlock()
+	screen.replaying = replaying
+}
+
+// pushReplayed sends a replayed event to the Screen, waiting for room regardless of its OverflowPolicy so that none are lost. It returns false if the Screen's context is done first.
+func (screen *Screen) pushReplayed(event Event) bool {
+	return screen.pushBlocking(event)
+}
@@ -0,0 +1,217 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/kettek/goro/glyphs"
+)
+
+/*
+The goRo screen format stores the full cell grid of a Screen. All values are little-endian.
+
+	Offset  Size  Description
+	0       4     Magic, the bytes "GORO"
+	4       2     Format version, currently 1
+	6       2     Columns
+	8       2     Rows
+
+The header is followed by Columns * Rows cells stored row by row, each 14 bytes:
+
+	Offset  Size  Description
+	0       4     Rune, as a signed 32-bit integer
+	4       1     Glyphs ID
+	5       4     Foreground as R, G, B, A
+	9       4     Background as R, G, B, A
+	13      1     Flags: 1 Blink, 2 Underline, 4 Bold, 8 Dim, 16 Reverse
+*/
+
+// ScreenFileVersion is the version of the screen format written by Screen.Save.
+const ScreenFileVersion = 1
+
+var screenFileMagic = [4]byte{'G', 'O', 'R', 'O'}
+
+// Errors returned when reading and writing screen files.
+var (
+	ErrScreenFileMagic   = errors.New("not a goro screen file")
+	ErrScreenFileVersion = errors.New("unsupported goro screen file version")
+	ErrScreenFileSize    = errors.New("screen is too large for a goro screen file")
+)
+
+// Style flags as stored in screen files.
+const (
+	styleFlagBlink = 1 << iota
+	styleFlagUnderline
+	styleFlagBold
+	styleFlagDim
+	styleFlagReverse
+)
+
+type screenFileHeader struct {
+	Magic   [4]byte
+	Version uint16
+	Columns uint16
+	Rows    uint16
+}
+
+type screenFileCell struct {
+	Rune       int32
+	Glyphs     uint8
+	Foreground [4]uint8
+	Background [4]uint8
+	Flags      uint8
+}
+
+// Save writes the Screen's cells to w using the goRo screen format. The most recently drawn state of each cell is written, whether or not it has been flushed.
+func (screen *Screen) Save(w io.Writer) error {
+	screen.cellsMutex.Lock()
+	header := screenFileHeader{
+		Magic:   screenFileMagic,
+		Version: ScreenFileVersion,
+	}
+	rows := len(screen.cells)
+	columns := 0
+	if rows > 0 {
+		columns = len(screen.cells[0])
+	}
+	if rows > math.MaxUint16 || columns > math.MaxUint16 {
+		screen.cellsMutex.Unlock()
+		return ErrScreenFileSize
+	}
+	header.Rows = uint16(rows)
+	header.Columns = uint16(columns)
+	fileCells := make([]screenFileCell, 0, rows*columns)
+	for y := range screen.cells {
+		for x := range screen.cells[y] {
+			cell := &screen.cells[y][x]
+			fileCells = append(fileCells, screenFileCell{
+				Rune:       int32(cell.PendingRune),
+				Glyphs:     uint8(cell.PendingGlyphs),
+				Foreground: colorToBytes(cell.PendingStyle.Foreground),
+				Background: colorToBytes(cell.PendingStyle.Background),
+				Flags:      styleToFlags(cell.PendingStyle),
+			})
+		}
+	}
+	screen.cellsMutex.Unlock()
+
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, fileCells); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Load reads a screen in the goRo screen format from r and draws it onto the Screen starting at its top-left corner. Cells outside of the Screen are discarded.
+func (screen *Screen) Load(r io.Reader) error {
+	source, err := LoadScreen(r)
+	if err != nil {
+		return err
+	}
+	for y := 0; y < source.Rows; y++ {
+		for x := 0; x < source.Columns; x++ {
+			cell := source.cells[y][x]
+			if screen.DrawRune(x, y, cell.PendingRune, cell.PendingStyle) != nil {
+				continue
+			}
+			screen.SetGlyphsID(x, y, cell.PendingGlyphs)
+		}
+	}
+	return nil
+}
+
+// LoadScreen reads a screen in the goRo screen format from r and returns it as a new virtual Screen sized to the stored columns and rows.
+func LoadScreen(r io.Reader) (*Screen, error) {
+	br := bufio.NewReader(r)
+	var header screenFileHeader
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Magic != screenFileMagic {
+		return nil, ErrScreenFileMagic
+	}
+	if header.Version != ScreenFileVersion {
+		return nil, ErrScreenFileVersion
+	}
+
+	screen, err := NewScreen(int(header.Columns), int(header.Rows))
+	if err != nil {
+		return nil, err
+	}
+	row := make([]screenFileCell, header.Columns)
+	for y := 0; y < int(header.Rows); y++ {
+		if err := binary.Read(br, binary.LittleEndian, row); err != nil {
+			return nil, err
+		}
+		for x, fileCell := range row {
+			cell := &screen.cells[y][x]
+			cell.PendingRune = rune(fileCell.Rune)
+			cell.PendingGlyphs = glyphs.ID(fileCell.Glyphs)
+			cell.PendingStyle = flagsToStyle(fileCell.Flags)
+			cell.PendingStyle.Foreground = bytesToColor(fileCell.Foreground)
+			cell.PendingStyle.Background = bytesToColor(fileCell.Background)
+			cell.Dirty = true
+		}
+	}
+	return screen, nil
+}
+
+func colorToBytes(c Color) [4]uint8 {
+	return [4]uint8{c.R, c.G, c.B, c.A}
+}
+
+func bytesToColor(b [4]uint8) Color {
+	return Color{R: b[0], G: b[1], B: b[2], A: b[3]}
+}
+
+func styleToFlags(s Style) (flags uint8) {
+	if s.Blink {
+		flags |= styleFlagBlink
+	}
+	if s.Underline {
+		flags |= styleFlagUnderline
+	}
+	if s.Bold {
+		flags |= styleFlagBold
+	}
+	if s.Dim {
+		flags |= styleFlagDim
+	}
+	if s.Reverse {
+		flags |= styleFlagReverse
+	}
+	return flags
+}
+
+func flagsToStyle(flags uint8) (s Style) {
+	s.Blink = flags&styleFlagBlink != 0
+	s.Underline = flags&styleFlagUnderline != 0
+	s.Bold = flags&styleFlagBold != 0
+	s.Dim = flags&styleFlagDim != 0
+	s.Reverse = flags&styleFlagReverse != 0
+	return s
+}
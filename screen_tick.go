@@ -0,0 +1,195 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"time"
+)
+
+// maxTickCatchUp limits how many ticks a Screen that has fallen behind sends at once. Time beyond this is discarded.
+const maxTickCatchUp = 5
+
+// tickState tracks a Screen's fixed timestep.
+type tickState struct {
+	step        time.Duration
+	accumulator time.Duration
+	last        time.Time
+	frame       uint64
+}
+
+// SetTickRate sets how many EventTicks are sent to the Screen each second. A rate of 0 or less stops ticks.
+func (screen *Screen) SetTickRate(rate float64) {
+	screen.tickMutex.Lock()
+	if rate > 0 {
+		screen.tick.step = time.Duration(float64(time.Second) / rate)
+	} else {
+		screen.tick.step = 0
+	}
+	screen.tick.accumulator = 0
+	screen.tick.last = time.Time{}
+	screen.tickMutex.Unlock()
+
+	if screens := screen.backend.Screens(); screens != nil {
+		screens.wakeTicks()
+	}
+}
+
+// TickRate returns how many EventTicks are sent to the Screen each second, or 0 if ticks are stopped.
+func (screen *Screen) TickRate() float64 {
+	screen.tickMutex.Lock()
+	defer screen.tickMutex.Unlock()
+	if screen.tick.step <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(screen.tick.step)
+}
+
+// advanceTicks accumulates the time since it was last called and sends an EventTick for each whole step. It returns the time until the next tick is due, or -1 if ticks are stopped.
+func (screen *Screen) advanceTicks(now time.Time) time.Duration {
+	screen.tickMutex.Lock()
+	state := &screen.tick
+	if state.step <= 0 {
+		screen.tickMutex.Unlock()
+		return -1
+	}
+	if state.last.IsZero() {
+		state.last = now
+	}
+	state.accumulator += now.Sub(state.last)
+	state.last = now
+	if state.accumulator > state.step*maxTickCatchUp {
+		state.accumulator = state.step * maxTickCatchUp
+	}
+	var ticks []EventTick
+	for state.accumulator >= state.step {
+		state.accumulator -= state.step
+		state.frame++
+		ticks = append(ticks, EventTick{
+			Delta: state.step,
+			Frame: state.frame,
+			Time:  now,
+		})
+	}
+	wait := state.step - state.accumulator
+	screen.tickMutex.Unlock()
+
+	for _, tick := range ticks {
+		screen.pushTick(tick)
+	}
+	return wait
+}
+
+// pushTick sends a tick to the Screen without ever waiting, whatever its OverflowPolicy. If the buffer is full, the tick is merged into the latest buffered tick, or dropped if there is none.
+func (screen *Screen) pushTick(tick EventTick) {
+	screen.eventMutex.Lock()
+	defer screen.eventMutex.Unlock()
+	if screen.replaying {
+		return
+	}
+	eventChan := screen.eventChan
+	select {
+	case eventChan <- tick:
+		return
+	default:
+	}
+
+	var events []Event
+	for len(events) < cap(eventChan) {
+		select {
+		case e := <-eventChan:
+			events = append(events, e)
+			continue
+		default:
+		}
+		break
+	}
+	merged := false
+	for i := len(events) - 1; i >= 0; i-- {
+		if previous, ok := events[i].(EventTick); ok {
+			previous.Delta += tick.Delta
+			previous.Frame = tick.Frame
+			previous.Time = tick.Time
+			events[i] = previous
+			merged = true
+			break
+		}
+	}
+	if !merged && len(events) < cap(eventChan) {
+		// A receiver made room while we drained.
+		events = append(events, tick)
+	}
+	for _, e := range events {
+		select {
+		case eventChan <- e:
+		default:
+			// A blocked sender took the room; the remaining events are dropped as if they were the newest.
+			return
+		}
+	}
+}
+
+// advanceTicks advances the ticks of each Screen, returning the time until the next tick is due, or -1 if no Screen has ticks.
+func (screens *Screens) advanceTicks(now time.Time) time.Duration {
+	next := time.Duration(-1)
+	for _, screen := range screens.List() {
+		if wait := screen.advanceTicks(now); wait >= 0 && (next < 0 || wait < next) {
+			next = wait
+		}
+	}
+	return next
+}
+
+// wakeTicks causes runTicks to recalculate when the next tick is due.
+func (screens *Screens) wakeTicks() {
+	select {
+	case screens.tickWake() <- struct{}{}:
+	default:
+	}
+}
+
+// tickWake returns the channel used by wakeTicks, creating it if needed.
+func (screens *Screens) tickWake() chan struct{} {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	if screens.tickWakeChan == nil {
+		screens.tickWakeChan = make(chan struct{}, 1)
+	}
+	return screens.tickWakeChan
+}
+
+// runTicks sends EventTicks to the Screens until ctx is done. It is used by backends that do not have their own update loop.
+func (screens *Screens) runTicks(ctx context.Context) {
+	wake := screens.tickWake()
+	for {
+		wait := screens.advanceTicks(time.Now())
+		if wait < 0 {
+			wait = time.Hour
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-wake:
+			timer.Stop()
+		}
+	}
+}
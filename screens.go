@@ -0,0 +1,297 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrScreenNotFound is returned when a Screen is not part of a Screens collection.
+var ErrScreenNotFound = errors.New("screen not found")
+
+// Screens is the z-ordered collection of Screens displayed by a Backend. Key events are routed to the focused Screen, mouse events to the top-most Screen under the cursor, and resize events to every Screen.
+type Screens struct {
+	backend Backend
+	ctx     context.Context
+	screens []*Screen // Sorted from bottom to top.
+	focused *Screen
+	damaged bool
+	mutex   sync.Mutex
+
+	tickWakeChan chan struct{}
+}
+
+// New creates a Screen of the given columns and rows positioned at x and y, adds it on top of the other Screens, and focuses it.
+func (screens *Screens) New(x, y, columns, rows int) (*Screen, error) {
+	screen := &Screen{}
+	if err := screen.Init(screens.backend); err != nil {
+		return nil, err
+	}
+	screen.ctx = screens.context()
+	screen.X, screen.Y = x, y
+	screen.Columns, screen.Rows = columns, rows
+	screen.AutoSize = false
+	if err := screen.Sync(); err != nil {
+		return nil, err
+	}
+	screens.Add(screen)
+	screens.Focus(screen)
+	return screen, nil
+}
+
+// Add adds the Screen on top of any other Screens with the same or lower z. The first Screen added becomes focused.
+func (screens *Screens) Add(screen *Screen) {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	for _, s := range screens.screens {
+		if s == screen {
+			return
+		}
+	}
+	screens.screens = append(screens.screens, screen)
+	screens.sort()
+	if screens.focused == nil {
+		screens.focused = screen
+	}
+	screens.damaged = true
+}
+
+// Remove removes the Screen. If it was focused, the top-most remaining Screen becomes focused.
+func (screens *Screens) Remove(screen *Screen) error {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	index := screens.indexOf(screen)
+	if index < 0 {
+		return ErrScreenNotFound
+	}
+	screens.screens = append(screens.screens[:index], screens.screens[index+1:]...)
+	if screens.focused == screen {
+		screens.focused = nil
+		if len(screens.screens) > 0 {
+			screens.focused = screens.screens[len(screens.screens)-1]
+		}
+	}
+	screens.damaged = true
+	return nil
+}
+
+// Focus sets the Screen that receives key events.
+func (screens *Screens) Focus(screen *Screen) error {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	if screens.indexOf(screen) < 0 {
+		return ErrScreenNotFound
+	}
+	screens.focused = screen
+	return nil
+}
+
+// Focused returns the Screen that receives key events.
+func (screens *Screens) Focused() *Screen {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	return screens.focused
+}
+
+// SetZ sets the Screen's z-order. Screens with a higher z are drawn above those with a lower z, and Screens with the same z are drawn in the order they were raised or added.
+func (screens *Screens) SetZ(screen *Screen, z int) error {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	index := screens.indexOf(screen)
+	if index < 0 {
+		return ErrScreenNotFound
+	}
+	// Move the screen to the end so that it is above others with the same z.
+	screens.screens = append(append(screens.screens[:index], screens.screens[index+1:]...), screen)
+	screen.z = z
+	screens.sort()
+	screens.damaged = true
+	return nil
+}
+
+// Raise moves the Screen above all other Screens with the same z.
+func (screens *Screens) Raise(screen *Screen) error {
+	return screens.SetZ(screen, screen.z)
+}
+
+// Lower moves the Screen below all other Screens with the same z.
+func (screens *Screens) Lower(screen *Screen) error {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	index := screens.indexOf(screen)
+	if index < 0 {
+		return ErrScreenNotFound
+	}
+	screens.screens = append([]*Screen{screen}, append(screens.screens[:index], screens.screens[index+1:]...)...)
+	screens.sort()
+	screens.damaged = true
+	return nil
+}
+
+// At returns the top-most Screen containing the given backend cell, or nil if there is none.
+func (screens *Screens) At(x, y int) *Screen {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	for i := len(screens.screens) - 1; i >= 0; i-- {
+		if screens.screens[i].contains(x, y) {
+			return screens.screens[i]
+		}
+	}
+	return nil
+}
+
+// List returns the Screens from bottom to top.
+func (screens *Screens) List() []*Screen {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	return append([]*Screen(nil), screens.screens...)
+}
+
+// setContext sets the context of the Screens and any Screens added later.
+func (screens *Screens) setContext(ctx context.Context) {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	screens.ctx = ctx
+	for _, screen := range screens.screens {
+		screen.ctx = ctx
+	}
+}
+
+// context returns the context given to setContext.
+func (screens *Screens) context() context.Context {
+	screens.mutex.Lock()
+	defer screens.mutex.Unlock()
+	return screens.ctx
+}
+
+// damage marks the Screens as needing a complete redraw, such as when a Screen has moved.
+func (screens *Screens) damage() {
+	screens.mutex.Lock()
+	screens.damaged = true
+	screens.mutex.Unlock()
+}
+
+// indexOf returns the index of the screen or -1. The mutex must be held.
+func (screens *Screens) indexOf(screen *Screen) int {
+	for i, s := range screens.screens {
+		if s == screen {
+			return i
+		}
+	}
+	return -1
+}
+
+// sort stably sorts the screens by z. The mutex must be held.
+func (screens *Screens) sort() {
+	sort.SliceStable(screens.screens, func(i, j int) bool {
+		return screens.screens[i].z < screens.screens[j].z
+	})
+}
+
+// sendKey sends a key event to the focused Screen.
+func (screens *Screens) sendKey(event EventKey) {
+	screen := screens.Focused()
+	if screen != nil && screen.UseKeys {
+		screen.pushEvent(event)
+	}
+}
+
+// sendMouse sends a mouse event to the top-most Screen under it, translated to that Screen's coordinates. Pressing a button focuses the Screen.
+func (screens *Screens) sendMouse(event EventMouse) {
+	screen := screens.At(event.X, event.Y)
+	if screen == nil {
+		return
+	}
+	if event.State {
+		screens.Focus(screen)
+	}
+	if screen.UseMouse {
+		event.X -= screen.X
+		event.Y -= screen.Y
+		screen.pushEvent(event)
+	}
+}
+
+// sendResize resizes each Screen that uses AutoSize to the given columns and rows, then sends each Screen the resize event.
+func (screens *Screens) sendResize(columns, rows int) {
+	for _, screen := range screens.List() {
+		if screen.AutoSize {
+			screen.SetSize(columns, rows)
+		}
+	}
+	screens.damage()
+	for _, screen := range screens.List() {
+		screen.pushEvent(EventResize{
+			Columns: columns,
+			Rows:    rows,
+		})
+	}
+}
+
+// redraw calls draw for each cell that needs to be redrawn and is not covered by a Screen above it, from the bottom Screen to the top. If the Screens have been damaged, clear is called first and every cell is redrawn. The position passed to draw is in backend cells.
+func (screens *Screens) redraw(clear func(), draw func(x, y int, screen *Screen, cell *Cell)) {
+	screens.mutex.Lock()
+	list := append([]*Screen(nil), screens.screens...)
+	damaged := screens.damaged
+	screens.damaged = false
+	screens.mutex.Unlock()
+
+	if damaged {
+		if clear != nil {
+			clear()
+		}
+		for _, screen := range list {
+			screen.ForceRedraw()
+		}
+	}
+
+	for i, screen := range list {
+		above := list[i+1:]
+		screen.cellsMutex.Lock()
+		if screen.Redraw {
+			for y := 0; y < len(screen.cells); y++ {
+				for x := 0; x < len(screen.cells[y]); x++ {
+					if !screen.cells[y][x].Redraw {
+						continue
+					}
+					screen.cells[y][x].Redraw = false
+					if coveredBy(above, screen.X+x, screen.Y+y) {
+						continue
+					}
+					draw(screen.X+x, screen.Y+y, screen, &screen.cells[y][x])
+				}
+			}
+			screen.Redraw = false
+		}
+		screen.cellsMutex.Unlock()
+	}
+}
+
+// coveredBy returns whether any of the given screens contain the backend cell at x and y.
+func coveredBy(screens []*Screen, x, y int) bool {
+	for _, screen := range screens {
+		if screen.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
@@ -28,14 +28,23 @@ import (
 
 // StyleToTCellStyle converts a provided Style into a tcell.Style.
 func StyleToTCellStyle(style Style) tcell.Style {
+	return styleToTCellStyle(style, ColorModeTrueColor, false, 0, 0)
+}
+
+// styleToTCellStyle converts a provided Style into a tcell.Style using the given ColorMode. If dither is set, the background is dithered based on the cell position x and y.
+func styleToTCellStyle(style Style, mode ColorMode, dither bool, x, y int) tcell.Style {
 	tStyle := tcell.StyleDefault
 	if style.Foreground != ColorNone {
-		tStyle = tStyle.Foreground(RGBAToTCellColor(style.Foreground))
+		tStyle = tStyle.Foreground(colorToTCellColor(style.Foreground, mode))
 	} else {
 		tStyle = tStyle.Foreground(-1)
 	}
 	if style.Background != ColorNone {
-		tStyle = tStyle.Background(RGBAToTCellColor(style.Background))
+		background := style.Background
+		if dither && mode != ColorModeTrueColor {
+			background = ditherColor(background, mode, x, y)
+		}
+		tStyle = tStyle.Background(colorToTCellColor(background, mode))
 	} else {
 		tStyle = tStyle.Background(-1)
 	}
@@ -45,6 +54,14 @@ func StyleToTCellStyle(style Style) tcell.Style {
 	return tStyle
 }
 
+// colorToTCellColor converts a Color to a tcell.Color, using the nearest palette color if mode is not ColorModeTrueColor.
+func colorToTCellColor(c Color, mode ColorMode) tcell.Color {
+	if mode == ColorModeTrueColor {
+		return RGBAToTCellColor(c)
+	}
+	return tcell.Color(quantizeColor(c, mode))
+}
+
 // RGBAToTCellColor converts a color.RGBA to a tcell.Color type.
 func RGBAToTCellColor(color color.RGBA) tcell.Color {
 	return tcell.NewRGBColor(int32(color.R), int32(color.G), int32(color.B)) | tcell.ColorIsRGB
@@ -0,0 +1,222 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// terminalInput converts the raw bytes sent by a terminal into EventKey and EventMouse events.
+type terminalInput struct {
+	pending []byte
+	lastCR  bool
+}
+
+// csiFinalKeyMap maps the final byte of CSI and SS3 sequences to keys.
+var csiFinalKeyMap = map[byte]Key{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// csiTildeKeyMap maps the numeric parameter of "CSI n ~" sequences to keys.
+var csiTildeKeyMap = map[int]Key{
+	1:  KeyHome,
+	2:  KeyInsert,
+	3:  KeyDelete,
+	4:  KeyEnd,
+	5:  KeyPageUp,
+	6:  KeyPageDown,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+// maxEscapeLength is the longest incomplete escape sequence kept until the next call to parse. Longer ones are discarded.
+const maxEscapeLength = 64
+
+// parse returns the events contained in data. Incomplete UTF-8 and escape sequences are kept until the next call. A lone escape at the end of data is treated as the escape key.
+func (input *terminalInput) parse(data []byte) (events []Event) {
+	data = append(input.pending, data...)
+	input.pending = nil
+
+	for len(data) > 0 {
+		b := data[0]
+		if input.lastCR && (b == '\n' || b == 0) {
+			input.lastCR = false
+			data = data[1:]
+			continue
+		}
+		input.lastCR = b == '\r'
+
+		switch {
+		case b == 0x1b:
+			event, n := input.parseEscape(data)
+			if n == 0 {
+				if len(data) > maxEscapeLength {
+					// Drop the escape and treat what follows as ordinary input.
+					data = data[1:]
+					continue
+				}
+				input.pending = append(input.pending, data...)
+				return events
+			}
+			if event != nil {
+				events = append(events, event)
+			}
+			data = data[n:]
+		case b == '\r' || b == '\n':
+			events = append(events, EventKey{Key: KeyEnter, Rune: '\r'})
+			data = data[1:]
+		case b == '\t':
+			events = append(events, EventKey{Key: KeyTab, Rune: '\t'})
+			data = data[1:]
+		case b == 0x7f || b == 0x08:
+			events = append(events, EventKey{Key: KeyBackspace})
+			data = data[1:]
+		case b >= 0x01 && b <= 0x1a:
+			events = append(events, EventKey{Key: KeyA + Key(b-1), Rune: rune('a' + b - 1), Ctrl: true})
+			data = data[1:]
+		case b < 0x20:
+			data = data[1:]
+		default:
+			if !utf8.FullRune(data) {
+				input.pending = append(input.pending, data...)
+				return events
+			}
+			r, n := utf8.DecodeRune(data)
+			events = append(events, runeToEventKey(r))
+			data = data[n:]
+		}
+	}
+	return events
+}
+
+// parseEscape parses an escape sequence at the start of data, returning its event, if any, and the number of bytes consumed. It consumes nothing if the sequence is incomplete.
+func (input *terminalInput) parseEscape(data []byte) (Event, int) {
+	if len(data) == 1 {
+		return EventKey{Key: KeyEscape}, 1
+	}
+	switch data[1] {
+	case '[':
+		// Find the final byte of the control sequence.
+		end := 2
+		for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+			end++
+		}
+		if end >= len(data) {
+			return nil, 0
+		}
+		return parseCSI(string(data[2:end]), data[end]), end + 1
+	case 'O':
+		if len(data) < 3 {
+			return nil, 0
+		}
+		if key, ok := csiFinalKeyMap[data[2]]; ok {
+			return EventKey{Key: key}, 3
+		}
+		return nil, 3
+	case 0x1b:
+		return EventKey{Key: KeyEscape}, 1
+	}
+	// Escape followed by a character is how terminals send Alt combinations.
+	if !utf8.FullRune(data[1:]) {
+		return nil, 0
+	}
+	r, n := utf8.DecodeRune(data[1:])
+	event := runeToEventKey(r)
+	event.Alt = true
+	return event, 1 + n
+}
+
+// parseCSI converts a CSI sequence's parameters and final byte into an event.
+func parseCSI(params string, final byte) Event {
+	// SGR mouse reports look like "<button;x;y" followed by M for presses and m for releases.
+	if strings.HasPrefix(params, "<") && (final == 'M' || final == 'm') {
+		fields := strings.Split(params[1:], ";")
+		if len(fields) != 3 {
+			return nil
+		}
+		button, _ := strconv.Atoi(fields[0])
+		x, _ := strconv.Atoi(fields[1])
+		y, _ := strconv.Atoi(fields[2])
+		return EventMouse{X: x - 1, Y: y - 1, Button: button & 3, State: final == 'M'}
+	}
+
+	fields := strings.Split(params, ";")
+	var event EventKey
+	if final == '~' {
+		n, _ := strconv.Atoi(fields[0])
+		key, ok := csiTildeKeyMap[n]
+		if !ok {
+			return nil
+		}
+		event.Key = key
+	} else if final == 'Z' {
+		event.Key = KeyTab
+		event.Shift = true
+	} else if key, ok := csiFinalKeyMap[final]; ok {
+		event.Key = key
+	} else {
+		return nil
+	}
+	// Modifiers are sent as a second parameter of 1 plus a bitmask.
+	if len(fields) > 1 {
+		if mod, err := strconv.Atoi(fields[1]); err == nil && mod > 1 {
+			mod--
+			event.Shift = event.Shift || mod&1 != 0
+			event.Alt = mod&2 != 0
+			event.Ctrl = mod&4 != 0
+			event.Meta = mod&8 != 0
+		}
+	}
+	return event
+}
+
+// runeToEventKey returns the EventKey for a typed rune.
+func runeToEventKey(r rune) EventKey {
+	event := EventKey{Rune: r}
+	if key, ok := RuneToKeyMap[r]; ok {
+		event.Key = key
+	}
+	if r >= 'A' && r <= 'Z' {
+		event.Shift = true
+	}
+	return event
+}
@@ -0,0 +1,268 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"strings"
+)
+
+// TextAlign is the horizontal alignment of text within a rectangle.
+type TextAlign uint8
+
+// These are our horizontal text alignments. AlignJustify stretches each wrapped line, except the last of each paragraph, to the full width.
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+	AlignJustify
+)
+
+// TextVAlign is the vertical alignment of text within a rectangle.
+type TextVAlign uint8
+
+// These are our vertical text alignments.
+const (
+	AlignTop TextVAlign = iota
+	AlignMiddle
+	AlignBottom
+)
+
+// DefaultTabWidth is the distance between tab stops when TextOptions.TabWidth is not set.
+const DefaultTabWidth = 4
+
+// TextOptions control how text is laid out.
+type TextOptions struct {
+	Align     TextAlign
+	VAlign    TextVAlign
+	Wrap      bool // Wrap breaks lines between words to fit the width.
+	Hyphenate bool // Hyphenate breaks words too long for a line with a hyphen, rather than anywhere.
+	Ellipsis  bool // Ellipsis marks text cut off by the width or height with '…'.
+	TabWidth  int
+}
+
+// textLine is a laid out line of text. Last is set if it ends a paragraph.
+type textLine struct {
+	runes []rune
+	last  bool
+}
+
+// LayoutText breaks text into the lines that DrawText would draw within the given width and height, with justification applied. A height of 0 or less does not limit the number of lines.
+func LayoutText(text string, width, height int, options TextOptions) []string {
+	lines := layoutText(text, width, height, options)
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = string(line.runes)
+	}
+	return result
+}
+
+// layoutText breaks text into lines as described by LayoutText.
+func layoutText(text string, width, height int, options TextOptions) []textLine {
+	if width <= 0 {
+		return nil
+	}
+	tabWidth := options.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = DefaultTabWidth
+	}
+
+	var lines []textLine
+	truncated := false
+	for _, paragraph := range strings.Split(text, "\n") {
+		runes := expandTabs([]rune(strings.TrimRight(paragraph, "\r")), tabWidth)
+		if !options.Wrap {
+			if len(runes) > width {
+				runes = truncateRunes(runes, width, options.Ellipsis)
+			}
+			lines = append(lines, textLine{runes: runes, last: true})
+		} else {
+			lines = append(lines, wrapRunes(runes, width, options.Hyphenate)...)
+		}
+		if height > 0 && len(lines) > height {
+			truncated = true
+			break
+		}
+	}
+
+	if truncated {
+		lines = lines[:height]
+		if options.Ellipsis {
+			last := &lines[height-1]
+			if len(last.runes) >= width {
+				last.runes = truncateRunes(last.runes, width, true)
+			} else {
+				last.runes = append(last.runes, '…')
+			}
+			last.last = true
+		}
+	}
+
+	if options.Align == AlignJustify {
+		for i := range lines {
+			if !lines[i].last {
+				lines[i].runes = justifyRunes(lines[i].runes, width)
+			}
+		}
+	}
+	return lines
+}
+
+// expandTabs replaces each tab with spaces up to the next tab stop.
+func expandTabs(runes []rune, tabWidth int) []rune {
+	if !strings.ContainsRune(string(runes), '\t') {
+		return runes
+	}
+	expanded := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if r != '\t' {
+			expanded = append(expanded, r)
+			continue
+		}
+		for spaces := tabWidth - len(expanded)%tabWidth; spaces > 0; spaces-- {
+			expanded = append(expanded, ' ')
+		}
+	}
+	return expanded
+}
+
+// truncateRunes shortens runes to width, ending with '…' if ellipsis is set.
+func truncateRunes(runes []rune, width int, ellipsis bool) []rune {
+	if !ellipsis {
+		return runes[:width]
+	}
+	truncated := append([]rune(nil), runes[:width-1]...)
+	return append(truncated, '…')
+}
+
+// wrapRunes breaks a paragraph into lines of at most width, preferring to break at spaces and after hyphens. Words longer than width are broken with a hyphen if hyphenate is set.
+func wrapRunes(runes []rune, width int, hyphenate bool) []textLine {
+	var lines []textLine
+	var line []rune
+	// pending is the whitespace between the line and the next word, which is dropped if the line breaks there.
+	var pending []rune
+	breakLine := func() {
+		lines = append(lines, textLine{runes: line})
+		line, pending = nil, nil
+	}
+
+	for i := 0; i < len(runes); {
+		if runes[i] == ' ' {
+			// Spaces starting the paragraph are kept as indentation.
+			if len(line) > 0 || len(lines) == 0 {
+				pending = append(pending, ' ')
+			}
+			i++
+			continue
+		}
+		// A word runs until a space, or through a hyphen that is followed by more of the word.
+		end := i
+		for end < len(runes) && runes[end] != ' ' {
+			end++
+			if runes[end-1] == '-' && end < len(runes) && runes[end] != ' ' {
+				break
+			}
+		}
+		word := runes[i:end]
+		i = end
+
+		if len(line)+len(pending)+len(word) <= width {
+			line = append(append(line, pending...), word...)
+			pending = nil
+			continue
+		}
+		if len(line) > 0 {
+			breakLine()
+		}
+		for len(word) > width {
+			if hyphenate && width > 1 {
+				line = append(append([]rune(nil), word[:width-1]...), '-')
+				word = word[width-1:]
+			} else {
+				line = append([]rune(nil), word[:width]...)
+				word = word[width:]
+			}
+			breakLine()
+		}
+		line = append(line, word...)
+	}
+	lines = append(lines, textLine{runes: line, last: true})
+	return lines
+}
+
+// justifyRunes widens the spaces between words so that the line fills width.
+func justifyRunes(runes []rune, width int) []rune {
+	indent := 0
+	for indent < len(runes) && runes[indent] == ' ' {
+		indent++
+	}
+	words := strings.Fields(string(runes))
+	if len(words) < 2 {
+		return runes
+	}
+	letters := indent
+	for _, word := range words {
+		letters += len([]rune(word))
+	}
+	gaps := len(words) - 1
+	spaces := width - letters
+	justified := append(make([]rune, 0, width), runes[:indent]...)
+	for i, word := range words {
+		justified = append(justified, []rune(word)...)
+		if i < gaps {
+			// Earlier gaps take the remainder so the extra space is spread evenly.
+			n := spaces / gaps
+			if i < spaces%gaps {
+				n++
+			}
+			for ; n > 0; n-- {
+				justified = append(justified, ' ')
+			}
+		}
+	}
+	return justified
+}
+
+// DrawText draws text within the rectangle using the style, laid out according to options. Cells of the rectangle not covered by text are left untouched. It returns the number of lines drawn.
+func (screen *Screen) DrawText(rect Rect, text string, s Style, options TextOptions) int {
+	lines := layoutText(text, rect.Width, rect.Height, options)
+
+	y := rect.Y
+	switch options.VAlign {
+	case AlignMiddle:
+		y += (rect.Height - len(lines)) / 2
+	case AlignBottom:
+		y += rect.Height - len(lines)
+	}
+
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	for i, line := range lines {
+		x := rect.X
+		switch options.Align {
+		case AlignCenter:
+			x += (rect.Width - len(line.runes)) / 2
+		case AlignRight:
+			x += rect.Width - len(line.runes)
+		}
+		for j, r := range line.runes {
+			screen.drawRune(x+j, y+i, r, s)
+		}
+	}
+	return len(lines)
+}
@@ -0,0 +1,98 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLayoutText(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		width, height int
+		options       TextOptions
+		want          []string
+	}{
+		{"single line", "hello", 10, 0, TextOptions{}, []string{"hello"}},
+		{"newlines", "a\r\nb\n\nc", 10, 0, TextOptions{}, []string{"a", "b", "", "c"}},
+		{"no width", "hello", 0, 0, TextOptions{}, []string{}},
+		{"cut", "abcdefgh", 5, 0, TextOptions{}, []string{"abcde"}},
+		{"cut with ellipsis", "abcdefgh", 5, 0, TextOptions{Ellipsis: true}, []string{"abcd…"}},
+		{"fits with ellipsis", "abcde", 5, 0, TextOptions{Ellipsis: true}, []string{"abcde"}},
+		{"wrap", "the quick brown fox", 10, 0, TextOptions{Wrap: true}, []string{"the quick", "brown fox"}},
+		{"wrap exact", "aa bb cc dd", 5, 0, TextOptions{Wrap: true}, []string{"aa bb", "cc dd"}},
+		{"wrap indented", "  ab cd", 5, 0, TextOptions{Wrap: true}, []string{"  ab", "cd"}},
+		{"wrap after hyphen", "well-known fact", 6, 0, TextOptions{Wrap: true}, []string{"well-", "known", "fact"}},
+		{"wrap long word", "abcdefghij", 4, 0, TextOptions{Wrap: true}, []string{"abcd", "efgh", "ij"}},
+		{"hyphenate", "abcdefghij", 4, 0, TextOptions{Wrap: true, Hyphenate: true}, []string{"abc-", "def-", "ghij"}},
+		{"hyphenate narrow", "abcde", 2, 0, TextOptions{Wrap: true, Hyphenate: true}, []string{"a-", "b-", "c-", "de"}},
+		{"hyphenate after a word", "ab cdefgh", 4, 0, TextOptions{Wrap: true, Hyphenate: true}, []string{"ab", "cde-", "fgh"}},
+		{"justify", "aa bb c dd", 9, 0, TextOptions{Wrap: true, Align: AlignJustify}, []string{"aa  bb  c", "dd"}},
+		{"justify unevenly", "a b c dd", 6, 0, TextOptions{Wrap: true, Align: AlignJustify}, []string{"a  b c", "dd"}},
+		{"justify paragraphs", "a b c\nd e", 7, 0, TextOptions{Wrap: true, Align: AlignJustify}, []string{"a b c", "d e"}},
+		{"justify one word", "abc defgh", 5, 0, TextOptions{Wrap: true, Align: AlignJustify}, []string{"abc", "defgh"}},
+		{"height", "one two three", 5, 2, TextOptions{Wrap: true}, []string{"one", "two"}},
+		{"height with ellipsis", "one two three four", 9, 1, TextOptions{Wrap: true, Ellipsis: true}, []string{"one two…"}},
+		{"full height with ellipsis", "abcde fgh", 5, 1, TextOptions{Wrap: true, Ellipsis: true}, []string{"abcd…"}},
+		{"height with ellipsis not needed", "one two", 9, 1, TextOptions{Wrap: true, Ellipsis: true}, []string{"one two"}},
+		{"tab", "a\tb", 10, 0, TextOptions{}, []string{"a   b"}},
+		{"tab width", "ab\tc\td", 10, 0, TextOptions{TabWidth: 3}, []string{"ab c  d"}},
+		{"tab at a stop", "abcd\te", 10, 0, TextOptions{}, []string{"abcd    e"}},
+		{"leading tab", "\tx", 10, 0, TextOptions{TabWidth: 2}, []string{"  x"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := LayoutText(test.text, test.width, test.height, test.options)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDrawText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		options TextOptions
+		want    []string
+		lines   int
+	}{
+		{"left", "ab cde", TextOptions{Wrap: true}, []string{".......", ".ab....", ".cde...", "......."}, 2},
+		{"right", "ab cde", TextOptions{Wrap: true, Align: AlignRight}, []string{".......", "....ab.", "...cde.", "......."}, 2},
+		{"center", "abc", TextOptions{Align: AlignCenter}, []string{".......", "..abc..", ".......", "......."}, 1},
+		{"middle", "ab", TextOptions{VAlign: AlignMiddle}, []string{".......", ".ab....", ".......", "......."}, 1},
+		{"bottom", "ab", TextOptions{VAlign: AlignBottom}, []string{".......", ".......", ".ab....", "......."}, 1},
+		{"cut", "a b c d e f g", TextOptions{Wrap: true, Ellipsis: true}, []string{".......", ".a b c.", ".d e ….", "......."}, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen := newTestScreen(t, 7, 4)
+			lines := screen.DrawText(Rect{1, 1, 5, 2}, test.text, Style{}, test.options)
+			if lines != test.lines {
+				t.Errorf("got %d lines, want %d", lines, test.lines)
+			}
+			if got := screenRunes(screen); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNoTheme is returned when a theme is not registered.
+var ErrNoTheme = errors.New("no such theme")
+
+// Theme maps semantic roles, such as "hp.low" or "ui.border", to Styles. Roles are dot separated, and a role without a style falls back to its parent role, then to the Parent theme, and finally to the "default" role.
+type Theme struct {
+	Name   string
+	Parent *Theme
+	styles map[string]Style
+	mutex  sync.RWMutex
+}
+
+// NewTheme returns an empty Theme with the given name that falls back to parent, which may be nil.
+func NewTheme(name string, parent *Theme) *Theme {
+	return &Theme{
+		Name:   name,
+		Parent: parent,
+		styles: make(map[string]Style),
+	}
+}
+
+// Set sets the style of role.
+func (theme *Theme) Set(role string, style Style) {
+	theme.mutex.Lock()
+	defer theme.mutex.Unlock()
+	theme.styles[role] = style
+}
+
+// Roles returns the roles the Theme itself defines, sorted.
+func (theme *Theme) Roles() []string {
+	theme.mutex.RLock()
+	defer theme.mutex.RUnlock()
+	roles := make([]string, 0, len(theme.styles))
+	for role := range theme.styles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Style returns the style of role. If neither the role, its parent roles, nor the "default" role are defined by the Theme or its Parents, an empty Style is returned.
+func (theme *Theme) Style(role string) Style {
+	for {
+		if style, ok := theme.lookup(role); ok {
+			return style
+		}
+		i := strings.LastIndexByte(role, '.')
+		if i < 0 {
+			break
+		}
+		role = role[:i]
+	}
+	style, _ := theme.lookup("default")
+	return style
+}
+
+// lookup returns the style of exactly role from the Theme or its Parents.
+func (theme *Theme) lookup(role string) (Style, bool) {
+	for t := theme; t != nil; t = t.Parent {
+		t.mutex.RLock()
+		style, ok := t.styles[role]
+		t.mutex.RUnlock()
+		if ok {
+			return style, true
+		}
+	}
+	return Style{}, false
+}
+
+// themes is the registry of themes by name.
+var (
+	themes      = make(map[string]*Theme)
+	themesMutex sync.Mutex
+)
+
+// RegisterTheme adds theme to the registry under its Name, replacing any theme of the same name.
+func RegisterTheme(theme *Theme) {
+	themesMutex.Lock()
+	defer themesMutex.Unlock()
+	themes[theme.Name] = theme
+}
+
+// LookupTheme returns the registered theme with the given name.
+func LookupTheme(name string) (*Theme, error) {
+	themesMutex.Lock()
+	defer themesMutex.Unlock()
+	theme, ok := themes[name]
+	if !ok {
+		return nil, ErrNoTheme
+	}
+	return theme, nil
+}
+
+// ThemeNames returns the names of the registered themes, sorted.
+func ThemeNames() []string {
+	themesMutex.Lock()
+	defer themesMutex.Unlock()
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Theme returns the Screen's theme, or DefaultTheme if none has been set.
+func (screen *Screen) Theme() *Theme {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	return screen.currentTheme()
+}
+
+// currentTheme returns the Screen's theme. The cells lock must be held.
+func (screen *Screen) currentTheme() *Theme {
+	if screen.theme == nil {
+		return DefaultTheme
+	}
+	return screen.theme
+}
+
+// SetTheme sets the Screen's theme, restyling every cell drawn with a role and marking the Screen to be redrawn.
+func (screen *Screen) SetTheme(theme *Theme) {
+	screen.cellsMutex.Lock()
+	screen.theme = theme
+	theme = screen.currentTheme()
+	for y := range screen.cells {
+		for x := range screen.cells[y] {
+			cell := &screen.cells[y][x]
+			if cell.PendingRole != "" {
+				cell.PendingStyle = theme.Style(cell.PendingRole)
+			}
+			if cell.Role != "" {
+				cell.Style = theme.Style(cell.Role)
+			}
+		}
+	}
+	screen.cellsMutex.Unlock()
+	screen.ForceRedraw()
+}
+
+// UseTheme sets the Screen's theme to the registered theme with the given name.
+func (screen *Screen) UseTheme(name string) error {
+	theme, err := LookupTheme(name)
+	if err != nil {
+		return err
+	}
+	screen.SetTheme(theme)
+	return nil
+}
+
+// DrawRole draws a given rune at the position of x and y with the style of role in the Screen's theme. The cell keeps the role, so it is restyled if the theme changes.
+func (screen *Screen) DrawRole(x int, y int, r rune, role string) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	return screen.drawRole(x, y, r, role)
+}
+
+// drawRole draws a given rune at the position of x and y with the style of role. The cells lock must be held.
+func (screen *Screen) drawRole(x int, y int, r rune, role string) error {
+	if err := screen.checkBounds(x, y); err != nil {
+		return err
+	}
+	if !screen.inClip(x, y) {
+		return nil
+	}
+	s := screen.currentTheme().Style(role)
+	cell := &screen.cells[y][x]
+	if cell.PendingRune == r && cell.PendingStyle == s && cell.PendingRole == role {
+		return nil
+	}
+	cell.PendingRune = r
+	cell.PendingStyle = s
+	cell.PendingRole = role
+	cell.Dirty = true
+	return nil
+}
+
+// DrawStringRole draws a string at the position of x and y with the style of role, iterating in the x direction as it goes.
+func (screen *Screen) DrawStringRole(x int, y int, str string, role string) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	return screen.drawStringRole(x, y, str, role)
+}
+
+// drawStringRole draws a string at the position of x and y with the style of role. The cells lock must be held.
+func (screen *Screen) drawStringRole(x int, y int, str string, role string) error {
+	origX := x
+	for _, r := range str {
+		if r == '\n' {
+			x = origX
+			y++
+		} else {
+			if err := screen.drawRole(x, y, r, role); err != nil {
+				return err
+			}
+			x++
+		}
+	}
+	return nil
+}
+
+// SetRole sets the style at the given location to that of role, keeping its rune.
+func (screen *Screen) SetRole(x int, y int, role string) error {
+	screen.cellsMutex.Lock()
+	defer screen.cellsMutex.Unlock()
+	return screen.setRole(x, y, role)
+}
+
+// setRole sets the style at the given location to that of role. The cells lock must be held.
+func (screen *Screen) setRole(x int, y int, role string) error {
+	if err := screen.checkBounds(x, y); err != nil {
+		return err
+	}
+	if !screen.inClip(x, y) {
+		return nil
+	}
+	s := screen.currentTheme().Style(role)
+	cell := &screen.cells[y][x]
+	if cell.PendingStyle == s && cell.PendingRole == role {
+		return nil
+	}
+	cell.PendingStyle = s
+	cell.PendingRole = role
+	cell.Dirty = true
+	return nil
+}
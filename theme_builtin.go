@@ -0,0 +1,104 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+// DefaultTheme is the theme used by Screens without one of their own. It is registered as "default".
+var DefaultTheme = NewTheme("default", nil)
+
+// Colors of the Okabe-Ito palette, which remain distinct with red-green color blindness.
+var (
+	okabeItoOrange        = Color{0xE6, 0x9F, 0x00, 0xFF}
+	okabeItoSkyBlue       = Color{0x56, 0xB4, 0xE9, 0xFF}
+	okabeItoBluishGreen   = Color{0x00, 0x9E, 0x73, 0xFF}
+	okabeItoYellow        = Color{0xF0, 0xE4, 0x42, 0xFF}
+	okabeItoVermillion    = Color{0xD5, 0x5E, 0x00, 0xFF}
+	okabeItoReddishPurple = Color{0xCC, 0x79, 0xA7, 0xFF}
+)
+
+// Colors that remain distinct with blue-yellow color blindness.
+var (
+	tritanRed  = Color{0xE4, 0x1A, 0x1C, 0xFF}
+	tritanTeal = Color{0x00, 0x9E, 0x9E, 0xFF}
+	tritanPink = Color{0xFF, 0x6D, 0xB6, 0xFF}
+)
+
+func init() {
+	DefaultTheme.Set("ui.border", Style{Foreground: ColorGray})
+	DefaultTheme.Set("ui.title", Style{Foreground: ColorWhite, Bold: true})
+	DefaultTheme.Set("ui.text", Style{Foreground: ColorSilver})
+	DefaultTheme.Set("ui.highlight", Style{Foreground: ColorBlack, Background: ColorSilver})
+	DefaultTheme.Set("ui.disabled", Style{Foreground: ColorGray, Dim: true})
+	DefaultTheme.Set("hp.high", Style{Foreground: ColorLime})
+	DefaultTheme.Set("hp.mid", Style{Foreground: ColorYellow})
+	DefaultTheme.Set("hp.low", Style{Foreground: ColorRed, Bold: true})
+	DefaultTheme.Set("item.common", Style{Foreground: ColorWhite})
+	DefaultTheme.Set("item.uncommon", Style{Foreground: ColorLime})
+	DefaultTheme.Set("item.rare", Style{Foreground: ColorBlue})
+	DefaultTheme.Set("item.epic", Style{Foreground: ColorFuchsia})
+	DefaultTheme.Set("item.legendary", Style{Foreground: Color{0xFF, 0xA5, 0x00, 0xFF}})
+	DefaultTheme.Set("msg.info", Style{Foreground: ColorSilver})
+	DefaultTheme.Set("msg.good", Style{Foreground: ColorLime})
+	DefaultTheme.Set("msg.warning", Style{Foreground: ColorYellow})
+	DefaultTheme.Set("msg.danger", Style{Foreground: ColorRed, Bold: true})
+	RegisterTheme(DefaultTheme)
+
+	// Deuteranopia and protanopia both confuse reds and greens, so they share the Okabe-Ito colors.
+	deuteranopia := NewTheme("deuteranopia", DefaultTheme)
+	deuteranopia.Set("hp.high", Style{Foreground: okabeItoSkyBlue})
+	deuteranopia.Set("hp.mid", Style{Foreground: okabeItoYellow})
+	deuteranopia.Set("hp.low", Style{Foreground: okabeItoVermillion, Bold: true})
+	deuteranopia.Set("item.uncommon", Style{Foreground: okabeItoBluishGreen})
+	deuteranopia.Set("item.rare", Style{Foreground: okabeItoSkyBlue})
+	deuteranopia.Set("item.epic", Style{Foreground: okabeItoReddishPurple})
+	deuteranopia.Set("item.legendary", Style{Foreground: okabeItoOrange})
+	deuteranopia.Set("msg.good", Style{Foreground: okabeItoSkyBlue})
+	deuteranopia.Set("msg.warning", Style{Foreground: okabeItoOrange})
+	deuteranopia.Set("msg.danger", Style{Foreground: okabeItoVermillion, Bold: true})
+	RegisterTheme(deuteranopia)
+	RegisterTheme(NewTheme("protanopia", deuteranopia))
+
+	tritanopia := NewTheme("tritanopia", DefaultTheme)
+	tritanopia.Set("hp.high", Style{Foreground: tritanTeal})
+	tritanopia.Set("hp.mid", Style{Foreground: tritanPink})
+	tritanopia.Set("hp.low", Style{Foreground: tritanRed, Bold: true})
+	tritanopia.Set("item.uncommon", Style{Foreground: tritanTeal})
+	tritanopia.Set("item.rare", Style{Foreground: tritanPink})
+	tritanopia.Set("item.epic", Style{Foreground: tritanRed})
+	tritanopia.Set("item.legendary", Style{Foreground: ColorWhite, Bold: true})
+	tritanopia.Set("msg.good", Style{Foreground: tritanTeal})
+	tritanopia.Set("msg.warning", Style{Foreground: tritanPink})
+	tritanopia.Set("msg.danger", Style{Foreground: tritanRed, Bold: true})
+	RegisterTheme(tritanopia)
+
+	// Monochrome relies on attributes alone, for achromatopsia or terminals without color.
+	monochrome := NewTheme("monochrome", nil)
+	monochrome.Set("ui.border", Style{Dim: true})
+	monochrome.Set("ui.title", Style{Bold: true})
+	monochrome.Set("ui.highlight", Style{Reverse: true})
+	monochrome.Set("ui.disabled", Style{Dim: true})
+	monochrome.Set("hp.mid", Style{Underline: true})
+	monochrome.Set("hp.low", Style{Bold: true, Reverse: true})
+	monochrome.Set("item.uncommon", Style{Underline: true})
+	monochrome.Set("item.rare", Style{Bold: true})
+	monochrome.Set("item.epic", Style{Bold: true, Underline: true})
+	monochrome.Set("item.legendary", Style{Reverse: true})
+	monochrome.Set("msg.warning", Style{Underline: true})
+	monochrome.Set("msg.danger", Style{Bold: true, Reverse: true})
+	RegisterTheme(monochrome)
+}
@@ -0,0 +1,125 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ErrThemeColor is returned when a theme file contains a color that cannot be parsed.
+var ErrThemeColor = errors.New("invalid theme color")
+
+// themeFile is the structure of JSON and TOML theme files. Parent names a registered theme.
+type themeFile struct {
+	Name   string                    `json:"name" toml:"name"`
+	Parent string                    `json:"parent" toml:"parent"`
+	Styles map[string]themeFileStyle `json:"styles" toml:"styles"`
+}
+
+// themeFileStyle is a style in a theme file. Colors are "#rrggbb", "#rrggbbaa", a CSS color name, or "none".
+type themeFileStyle struct {
+	Foreground string `json:"fg" toml:"fg"`
+	Background string `json:"bg" toml:"bg"`
+	Bold       bool   `json:"bold" toml:"bold"`
+	Underline  bool   `json:"underline" toml:"underline"`
+	Blink      bool   `json:"blink" toml:"blink"`
+	Dim        bool   `json:"dim" toml:"dim"`
+	Reverse    bool   `json:"reverse" toml:"reverse"`
+}
+
+// LoadThemeJSON reads a theme from JSON, such as {"name": "mine", "parent": "default", "styles": {"hp.low": {"fg": "#ff0000", "bold": true}}}. The theme is not registered.
+func LoadThemeJSON(r io.Reader) (*Theme, error) {
+	var file themeFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	return file.theme()
+}
+
+// LoadThemeTOML reads a theme from TOML, with the styles as tables such as [styles."hp.low"]. The theme is not registered.
+func LoadThemeTOML(r io.Reader) (*Theme, error) {
+	var file themeFile
+	if _, err := toml.DecodeReader(r, &file); err != nil {
+		return nil, err
+	}
+	return file.theme()
+}
+
+// theme converts the file into a Theme.
+func (file *themeFile) theme() (*Theme, error) {
+	var parent *Theme
+	if file.Parent != "" {
+		var err error
+		if parent, err = LookupTheme(file.Parent); err != nil {
+			return nil, err
+		}
+	}
+	theme := NewTheme(file.Name, parent)
+	for role, fileStyle := range file.Styles {
+		foreground, err := parseThemeColor(fileStyle.Foreground)
+		if err != nil {
+			return nil, err
+		}
+		background, err := parseThemeColor(fileStyle.Background)
+		if err != nil {
+			return nil, err
+		}
+		theme.Set(role, Style{
+			Foreground: foreground,
+			Background: background,
+			Bold:       fileStyle.Bold,
+			Underline:  fileStyle.Underline,
+			Blink:      fileStyle.Blink,
+			Dim:        fileStyle.Dim,
+			Reverse:    fileStyle.Reverse,
+		})
+	}
+	return theme, nil
+}
+
+// parseThemeColor parses a theme file color. An empty string is ColorNone.
+func parseThemeColor(s string) (Color, error) {
+	if s == "" || strings.EqualFold(s, "none") {
+		return ColorNone, nil
+	}
+	if !strings.HasPrefix(s, "#") {
+		if c, ok := CSSColor(s); ok {
+			return c, nil
+		}
+		return ColorNone, ErrThemeColor
+	}
+	s = s[1:]
+	if len(s) != 6 && len(s) != 8 {
+		return ColorNone, ErrThemeColor
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return ColorNone, ErrThemeColor
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xFF
+	}
+	return Color{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
@@ -0,0 +1,224 @@
+/*
+This file is a part of goRo, a library for writing roguelikes.
+Copyright (C) 2019 Ketchetwahmeegwun T. Southall
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Lesser General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Lesser General Public License for more details.
+
+You should have received a copy of the GNU Lesser General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package goro
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WebSocket opcodes from RFC 6455.
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// wsMaxMessageSize limits the size of messages we accept from clients.
+const wsMaxMessageSize = 1 << 20
+
+// wsAcceptGUID is appended to the client's key to produce the handshake's accept key.
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Errors returned by wsConn.
+var (
+	errWSHandshake   = errors.New("not a websocket handshake")
+	errWSOrigin      = errors.New("websocket origin not allowed")
+	errWSMessageSize = errors.New("websocket message too large")
+	errWSUnmasked    = errors.New("websocket client frame not masked")
+)
+
+// wsCloseProtocolError is the close status code sent when a client breaks the protocol.
+const wsCloseProtocolError = 1002
+
+// wsConn is a minimal server-side WebSocket connection, as described by RFC 6455.
+type wsConn struct {
+	conn       net.Conn
+	reader     *bufio.Reader
+	writeMutex sync.Mutex
+}
+
+// wsOriginAllowed returns whether the request's Origin matches its Host or is one of allowed. Requests without an Origin, which browsers always send, are allowed. An allowed entry of "*" allows any origin.
+func wsOriginAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, entry := range allowed {
+		if entry == "*" || strings.EqualFold(entry, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// wsUpgrade completes the WebSocket handshake for the request and takes over its connection. Requests from an origin other than the request's Host are refused unless listed in allowedOrigins.
+func wsUpgrade(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*wsConn, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Key") == "" {
+		http.Error(w, errWSHandshake.Error(), http.StatusBadRequest)
+		return nil, errWSHandshake
+	}
+	if !wsOriginAllowed(r, allowedOrigins) {
+		http.Error(w, errWSOrigin.Error(), http.StatusForbidden)
+		return nil, errWSOrigin
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, errWSHandshake.Error(), http.StatusInternalServerError)
+		return nil, errWSHandshake
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + wsAcceptGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, reader: rw.Reader}, nil
+}
+
+// ReadMessage returns the next text or binary message, answering pings and reassembling fragmented messages along the way.
+func (ws *wsConn) ReadMessage() (opcode byte, message []byte, err error) {
+	for {
+		fin, op, payload, err := ws.readFrame()
+		if err == errWSUnmasked {
+			ws.WriteMessage(wsOpClose, []byte{wsCloseProtocolError >> 8, wsCloseProtocolError & 0xFF})
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case wsOpPing:
+			ws.WriteMessage(wsOpPong, payload)
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			ws.WriteMessage(wsOpClose, nil)
+			return 0, nil, io.EOF
+		case wsOpText, wsOpBinary:
+			opcode = op
+			message = payload
+		case wsOpContinuation:
+			message = append(message, payload...)
+		}
+		if len(message) > wsMaxMessageSize {
+			return 0, nil, errWSMessageSize
+		}
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// readFrame reads a single frame, unmasking its payload. Clients must mask every frame, so unmasked frames return errWSUnmasked.
+func (ws *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(ws.reader, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[1]&0x80 == 0 {
+		err = errWSUnmasked
+		return
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(ws.reader, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(ws.reader, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > wsMaxMessageSize {
+		err = errWSMessageSize
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(ws.reader, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(ws.reader, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// WriteMessage writes payload as a single unmasked frame.
+func (ws *wsConn) WriteMessage(opcode byte, payload []byte) error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+
+	header := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126, byte(length>>8), byte(length))
+	default:
+		header = append(header, 127)
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(length))
+		header = append(header, ext[:]...)
+	}
+	if _, err := ws.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close closes the underlying connection.
+func (ws *wsConn) Close() error {
+	return ws.conn.Close()
+}